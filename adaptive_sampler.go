@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+// AdaptiveSampler tracks the change rate of each resource and throttles resources that change
+// faster than a threshold: above it, only 1 in sampleEvery changes is let through
+// (or none when sampleEvery is 0). A nil *AdaptiveSampler lets everything through
+type AdaptiveSampler struct {
+	threshold   int           // changes per window before a resource is throttled
+	window      time.Duration // sliding window the rate is measured over
+	sampleEvery int           // keep 1 in N changes while throttled (0 = suppress all)
+	mutex       sync.Mutex
+	states      map[string]*samplerState
+}
+
+// samplerState is the rate-tracking state of one resource
+type samplerState struct {
+	changes   []time.Time
+	throttled bool
+	since     time.Time
+	dropped   int
+	seen      int
+}
+
+// ThrottledResource describes a resource currently being throttled
+type ThrottledResource struct {
+	Resource string    `json:"resource"`
+	Rate     int       `json:"changes_per_window"`
+	Since    time.Time `json:"since"`
+	Dropped  int       `json:"dropped"`
+	Mode     string    `json:"mode"`
+}
+
+// NewAdaptiveSampler creates a sampler. Returns nil if threshold is 0 (disabled)
+func NewAdaptiveSampler(threshold int, window time.Duration, sampleEvery int) *AdaptiveSampler {
+	if threshold <= 0 {
+		return nil
+	}
+	return &AdaptiveSampler{
+		threshold:   threshold,
+		window:      window,
+		sampleEvery: sampleEvery,
+		states:      make(map[string]*samplerState),
+	}
+}
+
+// Allow records a change for the resource and reports whether it should be stored
+func (as *AdaptiveSampler) Allow(resourceKey string) bool {
+	if as == nil {
+		return true
+	}
+
+	as.mutex.Lock()
+	defer as.mutex.Unlock()
+
+	now := time.Now()
+	state, ok := as.states[resourceKey]
+	if !ok {
+		state = &samplerState{}
+		as.states[resourceKey] = state
+	}
+
+	// Slide the window
+	cutoff := now.Add(-as.window)
+	kept := state.changes[:0]
+	for _, t := range state.changes {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	state.changes = append(kept, now)
+	rate := len(state.changes)
+
+	switch {
+	case !state.throttled && rate > as.threshold:
+		state.throttled = true
+		state.since = now
+		state.dropped = 0
+		state.seen = 0
+		logWarn(fmt.Sprintf("🐢 Throttling %s: %d changes in %s exceeds %d (%s)",
+			resourceKey, rate, as.window, as.threshold, as.mode()),
+			"throttling resource", slog.String("resource", resourceKey), slog.Int("changes", rate),
+			slog.Duration("window", as.window), slog.Int("threshold", as.threshold), slog.String("mode", as.mode()))
+	case state.throttled && rate <= as.threshold/2:
+		// Hysteresis: only release once the rate has clearly dropped
+		state.throttled = false
+		logInfo(fmt.Sprintf("🐇 No longer throttling %s (%d changes dropped)", resourceKey, state.dropped),
+			"no longer throttling resource", slog.String("resource", resourceKey), slog.Int("dropped", state.dropped))
+	}
+
+	if !state.throttled {
+		return true
+	}
+
+	state.seen++
+	if as.sampleEvery > 0 && state.seen%as.sampleEvery == 0 {
+		return true
+	}
+	state.dropped++
+	return false
+}
+
+// Throttled returns the resources currently being throttled, sorted by resource key
+func (as *AdaptiveSampler) Throttled() []ThrottledResource {
+	throttled := make([]ThrottledResource, 0)
+	if as == nil {
+		return throttled
+	}
+
+	as.mutex.Lock()
+	defer as.mutex.Unlock()
+
+	for key, state := range as.states {
+		if !state.throttled {
+			continue
+		}
+		throttled = append(throttled, ThrottledResource{
+			Resource: key,
+			Rate:     len(state.changes),
+			Since:    state.since,
+			Dropped:  state.dropped,
+			Mode:     as.mode(),
+		})
+	}
+	sort.Slice(throttled, func(i, j int) bool {
+		return throttled[i].Resource < throttled[j].Resource
+	})
+	return throttled
+}
+
+// mode describes the throttling behavior
+func (as *AdaptiveSampler) mode() string {
+	if as.sampleEvery > 0 {
+		return fmt.Sprintf("sampling 1 in %d", as.sampleEvery)
+	}
+	return "suppressed"
+}
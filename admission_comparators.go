@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// compareWebhookConfigurations compares Mutating/ValidatingWebhookConfigurations webhook by webhook,
+// highlighting rules, failurePolicy and clientConfig changes.
+// Changes that weaken enforcement (Fail→Ignore, removed webhooks) are raised as alerts
+func compareWebhookConfigurations(old, new *unstructured.Unstructured) *ComparatorResult {
+	result := newComparatorResult()
+	kind := new.GetKind()
+
+	oldWebhooks, _, _ := unstructured.NestedSlice(old.Object, "webhooks")
+	newWebhooks, _, _ := unstructured.NestedSlice(new.Object, "webhooks")
+
+	oldByName, oldOrder := listItemsByName(oldWebhooks)
+	newByName, newOrder := listItemsByName(newWebhooks)
+
+	for _, name := range oldOrder {
+		if _, exists := newByName[name]; !exists {
+			result.addChange(fmt.Sprintf("webhooks[%s]", name), oldByName[name], nil)
+			result.Alerts = append(result.Alerts,
+				fmt.Sprintf("%s %s: webhook %q was removed", kind, new.GetName(), name))
+		}
+	}
+
+	for _, name := range newOrder {
+		newWebhook := newByName[name]
+		oldWebhook, exists := oldByName[name]
+		if !exists {
+			result.addChange(fmt.Sprintf("webhooks[%s]", name), nil, newWebhook)
+			continue
+		}
+
+		for _, field := range []string{"rules", "clientConfig", "namespaceSelector", "objectSelector", "sideEffects", "matchPolicy"} {
+			if !reflect.DeepEqual(oldWebhook[field], newWebhook[field]) {
+				result.addChange(fmt.Sprintf("webhooks[%s].%s", name, field), oldWebhook[field], newWebhook[field])
+			}
+		}
+
+		oldPolicy := webhookFailurePolicy(oldWebhook)
+		newPolicy := webhookFailurePolicy(newWebhook)
+		if oldPolicy != newPolicy {
+			result.addChange(fmt.Sprintf("webhooks[%s].failurePolicy", name), oldPolicy, newPolicy)
+			if oldPolicy == "Fail" && newPolicy == "Ignore" {
+				result.Alerts = append(result.Alerts,
+					fmt.Sprintf("%s %s: webhook %q failurePolicy changed Fail → Ignore (enforcement weakened)",
+						kind, new.GetName(), name))
+			}
+		}
+	}
+
+	return result
+}
+
+// webhookFailurePolicy returns the webhook's failurePolicy, applying the v1 API default ("Fail")
+func webhookFailurePolicy(webhook map[string]interface{}) string {
+	if policy, ok := webhook["failurePolicy"].(string); ok && policy != "" {
+		return policy
+	}
+	return "Fail"
+}
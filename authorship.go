@@ -0,0 +1,41 @@
+package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// unknownManager is reported when a change can't be attributed to a field manager
+const unknownManager = "unknown"
+
+// changesByManagerMetric counts stored changes per field manager and kind
+var changesByManagerMetric = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "changes_by_manager_total",
+	Help: "Stored changes by field manager and kind.",
+}, []string{"manager", "kind"})
+
+// recordChangeAuthor increments the change counter for a manager and kind
+func recordChangeAuthor(manager string, kind string) {
+	changesByManagerMetric.WithLabelValues(manager, kind).Inc()
+}
+
+// changeAuthor attributes an object's latest change to the field manager with the most recent
+// managedFields entry (e.g. kubectl-client-side-apply, argocd-controller). obj may be an
+// *unstructured.Unstructured or a decoded JSON object
+func changeAuthor(obj interface{}) string {
+	var u *unstructured.Unstructured
+	switch o := obj.(type) {
+	case *unstructured.Unstructured:
+		u = o
+	case map[string]interface{}:
+		u = &unstructured.Unstructured{Object: o}
+	default:
+		return unknownManager
+	}
+
+	if manager := latestFieldManager(u.GetManagedFields()); manager != "" {
+		return manager
+	}
+	return unknownManager
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Canonicalizer rewrites an object into a canonical form before it is diffed, e.g. by stripping
+// values the API server fills in by default. It modifies obj in place; the pipeline only ever
+// passes it a copy, so stored objects keep the server's form
+type Canonicalizer func(obj *unstructured.Unstructured)
+
+// defaultCanonicalizers returns the built-in canonicalizers keyed by resource kind
+func defaultCanonicalizers() map[string]Canonicalizer {
+	return map[string]Canonicalizer{
+		"Gateway": canonicalizeGateway,
+		"Service": canonicalizeService,
+	}
+}
+
+// ParseCanonicalKinds parses a comma-separated list of kinds to canonicalize (e.g. "Gateway,Service")
+// and checks each has a built-in canonicalizer
+func ParseCanonicalKinds(value string) ([]string, error) {
+	available := defaultCanonicalizers()
+	kinds := make([]string, 0)
+	for _, part := range strings.Split(value, ",") {
+		kind := strings.TrimSpace(part)
+		if kind == "" {
+			continue
+		}
+		if _, ok := available[kind]; !ok {
+			known := make([]string, 0, len(available))
+			for name := range available {
+				known = append(known, name)
+			}
+			sort.Strings(known)
+			return nil, fmt.Errorf("no canonicalizer for kind %q (available: %s)", kind, strings.Join(known, ", "))
+		}
+		kinds = append(kinds, kind)
+	}
+	return kinds, nil
+}
+
+// gatewayListenerDefaultAllowedRoutes is what the API server defaults a listener's allowedRoutes to
+var gatewayListenerDefaultAllowedRoutes = map[string]interface{}{
+	"namespaces": map[string]interface{}{"from": "Same"},
+}
+
+// canonicalizeGateway strips the allowedRoutes the API server injects into every Gateway listener
+// that doesn't set it, so a re-applied minimal spec doesn't diff against the defaulted one
+func canonicalizeGateway(obj *unstructured.Unstructured) {
+	listeners, found, err := unstructured.NestedSlice(obj.Object, "spec", "listeners")
+	if err != nil || !found {
+		return
+	}
+
+	for _, item := range listeners {
+		listener, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if reflect.DeepEqual(listener["allowedRoutes"], gatewayListenerDefaultAllowedRoutes) {
+			delete(listener, "allowedRoutes")
+		}
+	}
+	_ = unstructured.SetNestedSlice(obj.Object, listeners, "spec", "listeners")
+}
+
+// canonicalizeService strips the port defaults the API server injects: protocol TCP and a
+// targetPort equal to the port
+func canonicalizeService(obj *unstructured.Unstructured) {
+	ports, found, err := unstructured.NestedSlice(obj.Object, "spec", "ports")
+	if err != nil || !found {
+		return
+	}
+
+	for _, item := range ports {
+		port, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if port["protocol"] == "TCP" {
+			delete(port, "protocol")
+		}
+		if targetPort, ok := port["targetPort"]; ok && numbersEqual(targetPort, port["port"]) {
+			delete(port, "targetPort")
+		}
+	}
+	_ = unstructured.SetNestedSlice(obj.Object, ports, "spec", "ports")
+}
+
+// numbersEqual compares two JSON numbers that may have been decoded as int64 or float64
+func numbersEqual(a, b interface{}) bool {
+	toFloat := func(v interface{}) (float64, bool) {
+		switch n := v.(type) {
+		case int64:
+			return float64(n), true
+		case float64:
+			return n, true
+		}
+		return 0, false
+	}
+	x, xOk := toFloat(a)
+	y, yOk := toFloat(b)
+	return xOk && yOk && x == y
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Output modes for --output
+const (
+	OutputText   = "text"   // human-readable logs on stdout only
+	OutputNDJSON = "ndjson" // stored changes as NDJSON on stdout, human-readable logs on stderr
+)
+
+// NDJSONWriter writes values as newline-delimited JSON, one compact object per line.
+// Each line is written with a single Write call, so concurrent writers never interleave lines
+type NDJSONWriter struct {
+	writer io.Writer
+	mutex  sync.Mutex
+}
+
+// NewNDJSONWriter creates an NDJSON writer on w (typically os.Stdout)
+func NewNDJSONWriter(w io.Writer) *NDJSONWriter {
+	return &NDJSONWriter{writer: w}
+}
+
+// Write marshals v and writes it as one line
+func (nw *NDJSONWriter) Write(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal NDJSON record: %w", err)
+	}
+	data = append(data, '\n')
+
+	nw.mutex.Lock()
+	defer nw.mutex.Unlock()
+	if _, err := nw.writer.Write(data); err != nil {
+		return fmt.Errorf("failed to write NDJSON record: %w", err)
+	}
+	return nil
+}
+
+// SetChangeOutput makes the pipeline also write every stored change to out as a ResourceChange
+// record (nil disables)
+func (ep *EventPipeline) SetChangeOutput(out *NDJSONWriter) {
+	ep.changeOutput = out
+}
+
+// emitChange writes a stored change to the change output, if one is set
+func (ep *EventPipeline) emitChange(event ResourceEvent, oldObj interface{}, generation int64, details *ChangeDetails) {
+	if ep.changeOutput == nil {
+		return
+	}
+
+	change := ep.buildResourceChange(event, oldObj, details)
+	change.Version = generation
+	if err := ep.changeOutput.Write(change); err != nil {
+		logError(fmt.Sprintf("⚠️  %v", err), "failed to write change record",
+			append(resourceAttrs(event.ResourceKind, event.Namespace, event.Name), slog.String("error", err.Error()))...)
+	}
+}
+
+// buildResourceChange builds the change record of a stored event, without a version
+func (ep *EventPipeline) buildResourceChange(event ResourceEvent, oldObj interface{}, details *ChangeDetails) ResourceChange {
+	var oldMap, newMap map[string]interface{}
+	if old, ok := oldObj.(*unstructured.Unstructured); ok {
+		oldMap = old.Object
+	}
+	if new, ok := event.Object.(*unstructured.Unstructured); ok {
+		newMap = new.Object
+	}
+
+	change := ResourceChange{
+		ResourceKind: event.ResourceKind,
+		Namespace:    event.Namespace,
+		ResourceName: event.Name,
+		Timestamp:    event.Timestamp,
+		Object:       event.Object,
+		Changes:      BuildChangeMap(oldMap, newMap),
+		FieldChanges: details.FieldChanges,
+		ImageChanges: details.ImageChanges,
+		RouteChanges: details.RouteChanges,
+		RootOwner:    event.RootOwner,
+		DiffSkipped:  details.DiffSkipped,
+	}
+	if ep.redisManager != nil {
+		change.CorrelationID = ep.redisManager.correlationID(event.Object)
+	}
+	return change
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Summary returns a concise, human-readable description of the change,
+// e.g. "listeners: +https:443, labels: env=prod→staging"
+func (rc ResourceChange) Summary() string {
+	return SummarizeChanges(rc.Changes)
+}
+
+// SummarizeChanges builds a summary from a changes map whose values are {"old": ..., "new": ...} pairs.
+// A "spec" entry is expanded so each changed spec field is summarized on its own
+func SummarizeChanges(changes map[string]interface{}) string {
+	if len(changes) == 0 {
+		return ""
+	}
+
+	parts := make([]string, 0, len(changes))
+	for _, name := range sortedKeys(changes) {
+		oldValue, newValue, ok := oldNewPair(changes[name])
+		if !ok {
+			parts = append(parts, name+": changed")
+			continue
+		}
+
+		if name == "spec" {
+			oldSpec, _ := oldValue.(map[string]interface{})
+			newSpec, _ := newValue.(map[string]interface{})
+			for _, field := range unionKeys(oldSpec, newSpec) {
+				if !reflect.DeepEqual(oldSpec[field], newSpec[field]) {
+					parts = append(parts, field+": "+summarizeValueChange(oldSpec[field], newSpec[field]))
+				}
+			}
+			continue
+		}
+
+		parts = append(parts, name+": "+summarizeValueChange(oldValue, newValue))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// BuildChangeMap compares two raw (JSON-decoded) objects and returns a changes map
+// covering labels, annotations and spec, in the same shape the pipeline uses
+func BuildChangeMap(oldObj, newObj map[string]interface{}) map[string]interface{} {
+	changes := make(map[string]interface{})
+
+	oldMeta, _ := oldObj["metadata"].(map[string]interface{})
+	newMeta, _ := newObj["metadata"].(map[string]interface{})
+	for _, field := range []string{"labels", "annotations"} {
+		if !reflect.DeepEqual(oldMeta[field], newMeta[field]) {
+			changes[field] = map[string]interface{}{"old": oldMeta[field], "new": newMeta[field]}
+		}
+	}
+
+	if !reflect.DeepEqual(oldObj["spec"], newObj["spec"]) {
+		changes["spec"] = map[string]interface{}{"old": oldObj["spec"], "new": newObj["spec"]}
+	}
+
+	return changes
+}
+
+// summarizeValueChange describes how a single value changed
+func summarizeValueChange(oldValue, newValue interface{}) string {
+	oldValue, newValue = normalizeStringMap(oldValue), normalizeStringMap(newValue)
+
+	oldMap, oldIsMap := oldValue.(map[string]interface{})
+	newMap, newIsMap := newValue.(map[string]interface{})
+	if (oldIsMap || oldValue == nil) && (newIsMap || newValue == nil) && (oldIsMap || newIsMap) {
+		return summarizeMapChange(oldMap, newMap)
+	}
+
+	oldList, oldIsList := oldValue.([]interface{})
+	newList, newIsList := newValue.([]interface{})
+	if (oldIsList || oldValue == nil) && (newIsList || newValue == nil) && (oldIsList || newIsList) {
+		return summarizeListChange(oldList, newList)
+	}
+
+	return fmt.Sprintf("%s→%s", summaryScalar(oldValue), summaryScalar(newValue))
+}
+
+// summarizeMapChange lists added (+k=v), removed (-k) and modified (k=old→new) keys
+func summarizeMapChange(oldMap, newMap map[string]interface{}) string {
+	parts := make([]string, 0)
+	for _, key := range unionKeys(oldMap, newMap) {
+		oldValue, inOld := oldMap[key]
+		newValue, inNew := newMap[key]
+		switch {
+		case !inOld:
+			parts = append(parts, fmt.Sprintf("+%s=%s", key, summaryScalar(newValue)))
+		case !inNew:
+			parts = append(parts, "-"+key)
+		case !reflect.DeepEqual(oldValue, newValue):
+			parts = append(parts, fmt.Sprintf("%s=%s→%s", key, summaryScalar(oldValue), summaryScalar(newValue)))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// summarizeListChange lists added (+item), removed (-item) and modified (~item) named entries.
+// Unnamed lists are summarized by length
+func summarizeListChange(oldList, newList []interface{}) string {
+	oldByName, oldOrder := listItemsByName(oldList)
+	newByName, newOrder := listItemsByName(newList)
+
+	_, oldUnnamed := oldByName[""]
+	_, newUnnamed := newByName[""]
+	if oldUnnamed || newUnnamed || len(oldByName)+len(newByName) == 0 {
+		return fmt.Sprintf("%d→%d items", len(oldList), len(newList))
+	}
+
+	parts := make([]string, 0)
+	for _, name := range newOrder {
+		oldItem, exists := oldByName[name]
+		if !exists {
+			parts = append(parts, "+"+listItemLabel(newByName[name]))
+		} else if !reflect.DeepEqual(oldItem, newByName[name]) {
+			parts = append(parts, "~"+listItemLabel(newByName[name]))
+		}
+	}
+	for _, name := range oldOrder {
+		if _, exists := newByName[name]; !exists {
+			parts = append(parts, "-"+listItemLabel(oldByName[name]))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// listItemLabel renders a named list item as "name" or "name:port" when it has a port
+func listItemLabel(item map[string]interface{}) string {
+	name, _ := item["name"].(string)
+	if port, ok := item["port"]; ok {
+		return fmt.Sprintf("%s:%v", name, port)
+	}
+	return name
+}
+
+// summaryScalar formats a value compactly for summaries
+func summaryScalar(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "<none>"
+	case map[string]interface{}, []interface{}:
+		return "{…}"
+	case string:
+		return v
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// normalizeStringMap converts map[string]string (labels/annotations from typed getters)
+// into map[string]interface{} so it is summarized key by key
+func normalizeStringMap(value interface{}) interface{} {
+	stringMap, ok := value.(map[string]string)
+	if !ok {
+		return value
+	}
+	if stringMap == nil {
+		return nil
+	}
+	result := make(map[string]interface{}, len(stringMap))
+	for key, v := range stringMap {
+		result[key] = v
+	}
+	return result
+}
+
+// oldNewPair extracts the "old" and "new" values from a change entry
+func oldNewPair(change interface{}) (interface{}, interface{}, bool) {
+	changeMap, ok := change.(map[string]interface{})
+	if !ok {
+		return nil, nil, false
+	}
+	oldValue, hasOld := changeMap["old"]
+	newValue, hasNew := changeMap["new"]
+	if !hasOld && !hasNew {
+		return nil, nil, false
+	}
+	return oldValue, newValue, true
+}
+
+// sortedKeys returns the keys of a map in sorted order
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// unionKeys returns the sorted union of the keys of two maps
+func unionKeys(a, b map[string]interface{}) []string {
+	union := make(map[string]interface{}, len(a)+len(b))
+	for key := range a {
+		union[key] = nil
+	}
+	for key := range b {
+		union[key] = nil
+	}
+	return sortedKeys(union)
+}
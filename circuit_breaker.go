@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// BreakerState is the state of the Kubernetes API circuit breaker
+type BreakerState int64
+
+const (
+	BreakerClosed   BreakerState = 0 // calls flow normally
+	BreakerHalfOpen BreakerState = 1 // a single probe call is allowed through
+	BreakerOpen     BreakerState = 2 // all calls wait for the cooldown to expire
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerHalfOpen:
+		return "half-open"
+	case BreakerOpen:
+		return "open"
+	}
+	return "unknown"
+}
+
+// Breaker metrics, served on /metrics by the HTTP server
+var (
+	breakerStateMetric = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "kube_api_breaker_state",
+		Help: "Kubernetes API circuit breaker state (0 closed, 1 half-open, 2 open).",
+	})
+	breakerTripsMetric = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "kube_api_breaker_trips_total",
+		Help: "Times the Kubernetes API circuit breaker opened.",
+	})
+)
+
+// CircuitBreaker is shared by all watchers so that an API server outage backs them off together
+// instead of each one reconnect-looping on its own
+type CircuitBreaker struct {
+	failureThreshold int
+	openDuration     time.Duration
+
+	mutex    sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// NewCircuitBreaker creates a breaker that opens after failureThreshold consecutive failures
+// and stays open for openDuration before allowing a half-open probe
+func NewCircuitBreaker(failureThreshold int, openDuration time.Duration) *CircuitBreaker {
+	if failureThreshold < 1 {
+		failureThreshold = 1
+	}
+	breakerStateMetric.Set(float64(BreakerClosed))
+	return &CircuitBreaker{
+		failureThreshold: failureThreshold,
+		openDuration:     openDuration,
+		state:            BreakerClosed,
+	}
+}
+
+// Do waits until the breaker permits a call, runs fn and records its outcome. Returns ctx's
+// error without calling fn if ctx is done while waiting. A nil breaker just runs fn
+func (cb *CircuitBreaker) Do(ctx context.Context, fn func() error) error {
+	if cb == nil {
+		return fn()
+	}
+	if err := cb.waitForPermit(ctx); err != nil {
+		return err
+	}
+	err := fn()
+	cb.record(err)
+	return err
+}
+
+// State returns the current breaker state
+func (cb *CircuitBreaker) State() BreakerState {
+	if cb == nil {
+		return BreakerClosed
+	}
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+	return cb.state
+}
+
+// waitForPermit blocks while the breaker is open or another half-open probe is in flight,
+// or until ctx is done
+func (cb *CircuitBreaker) waitForPermit(ctx context.Context) error {
+	for {
+		cb.mutex.Lock()
+		wait := time.Second
+
+		switch cb.state {
+		case BreakerClosed:
+			cb.mutex.Unlock()
+			return nil
+
+		case BreakerOpen:
+			remaining := cb.openDuration - time.Since(cb.openedAt)
+			if remaining <= 0 {
+				cb.setState(BreakerHalfOpen)
+				cb.probing = true
+				cb.mutex.Unlock()
+				return nil
+			}
+			wait = remaining
+
+		case BreakerHalfOpen:
+			if !cb.probing {
+				cb.probing = true
+				cb.mutex.Unlock()
+				return nil
+			}
+		}
+
+		cb.mutex.Unlock()
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// record updates the breaker with the outcome of a call
+func (cb *CircuitBreaker) record(err error) {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	// A cancelled call says nothing about the API server; a cancelled probe lets the next one through
+	if isContextError(err) {
+		cb.probing = false
+		return
+	}
+
+	if !isBreakerFailure(err) {
+		cb.failures = 0
+		if cb.state == BreakerHalfOpen {
+			cb.probing = false
+			cb.setState(BreakerClosed)
+		}
+		return
+	}
+
+	if cb.state == BreakerHalfOpen {
+		cb.probing = false
+		cb.trip()
+		return
+	}
+
+	cb.failures++
+	if cb.state == BreakerClosed && cb.failures >= cb.failureThreshold {
+		cb.trip()
+	}
+}
+
+// trip opens the breaker (caller holds the mutex)
+func (cb *CircuitBreaker) trip() {
+	cb.openedAt = time.Now()
+	cb.failures = 0
+	breakerTripsMetric.Inc()
+	cb.setState(BreakerOpen)
+}
+
+// setState changes state, updates the metric and logs the transition (caller holds the mutex)
+func (cb *CircuitBreaker) setState(state BreakerState) {
+	if cb.state == state {
+		return
+	}
+	logWarn(fmt.Sprintf("🔌 Kubernetes API circuit breaker: %s → %s", cb.state, state), "circuit breaker state changed",
+		slog.String("from", cb.state.String()), slog.String("to", state.String()))
+	cb.state = state
+	breakerStateMetric.Set(float64(state))
+}
+
+// isContextError reports whether err comes from the caller's context being cancelled or
+// timing out rather than from the API server
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// isBreakerFailure reports whether err indicates the API server is unhealthy.
+// Client-side errors such as NotFound (missing CRD) or Forbidden don't count
+func isBreakerFailure(err error) bool {
+	if err == nil || isContextError(err) {
+		return false
+	}
+	if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) || apierrors.IsUnauthorized(err) ||
+		apierrors.IsBadRequest(err) || apierrors.IsInvalid(err) {
+		return false
+	}
+	return true
+}
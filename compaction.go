@@ -0,0 +1,177 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// compactionIgnoredMetadata lists metadata fields that change without a meaningful edit
+var compactionIgnoredMetadata = []string{"resourceVersion", "managedFields", "generation"}
+
+// Compactor periodically removes redundant entries from each resource's history:
+// versions identical to their predecessor once status and bookkeeping metadata are ignored.
+// Stored objects are never rewritten, so the generation of every remaining entry is preserved;
+// only versions stored as diffs are re-encoded when the version they were based on is removed
+type Compactor struct {
+	redisManager *RedisManager
+	interval     time.Duration
+}
+
+// NewCompactor creates a compactor running every interval
+func NewCompactor(redisManager *RedisManager, interval time.Duration) *Compactor {
+	return &Compactor{
+		redisManager: redisManager,
+		interval:     interval,
+	}
+}
+
+// Start runs compaction on the configured schedule (blocking)
+func (c *Compactor) Start() {
+	logInfo(fmt.Sprintf("🧹 History compaction enabled (every %s)", c.interval),
+		"history compaction enabled", slog.Duration("interval", c.interval))
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		removed, err := c.CompactAll()
+		if err != nil {
+			logWarn(fmt.Sprintf("⚠️  History compaction failed: %v", err),
+				"history compaction failed", slog.String("error", err.Error()))
+			continue
+		}
+		if removed > 0 {
+			logInfo(fmt.Sprintf("🧹 History compaction removed %d redundant entries", removed),
+				"history compaction removed entries", slog.Int("removed", removed))
+		}
+	}
+}
+
+// CompactAll compacts the history of every stored resource and returns the number of entries removed
+func (c *Compactor) CompactAll() (int, error) {
+	keys, err := c.redisManager.GetAllResourceKeys()
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, key := range keys {
+		removed, err := c.CompactResource(key)
+		if err != nil {
+			logWarn(fmt.Sprintf("⚠️  Failed to compact %s: %v", key, err),
+				"failed to compact history", slog.String("resource", key), slog.String("error", err.Error()))
+			continue
+		}
+		total += removed
+	}
+	return total, nil
+}
+
+// CompactResource removes entries of one resource's history whose field diff against the
+// previous kept entry is empty. Baseline snapshots are never removed. Returns the number of entries removed
+func (c *Compactor) CompactResource(resourceKey string) (int, error) {
+	// Hold the resource lock so no push interleaves with the rewrite
+	unlock := c.redisManager.LockResource(resourceKey)
+	defer unlock()
+
+	entries, err := c.redisManager.GetResourceHistoryRaw(resourceKey)
+	if err != nil {
+		return 0, err
+	}
+	if len(entries) < 2 {
+		return 0, nil
+	}
+
+	// Versions stored as diffs are compared by their rebuilt full objects
+	history := decodeHistory(entries)
+	isDiff := make([]bool, len(history))
+	for i, entry := range history {
+		isDiff[i] = isDiffEntry(entry)
+	}
+	reconstructHistory(history)
+
+	// Entries are most recent first; walk from the oldest so each version is compared to its predecessor
+	kept := make([]string, 0, len(entries))
+	var previous, previousFull map[string]interface{}
+	skipped := false // an entry was removed since the last kept one
+	for i := len(entries) - 1; i >= 0; i-- {
+		stored := history[i]
+		if stored == nil || isDiffEntry(stored) {
+			kept = append(kept, entries[i]) // leave entries we can't interpret alone
+			continue
+		}
+
+		// Baselines are deliberate point-in-time evidence, kept even when nothing changed
+		full := unwrapStoredObject(stored)
+		current := normalizeForCompaction(full)
+		baseline := isBaselineEntry(stored)
+		if previous != nil && !baseline {
+			changes, err := GetFieldChanges(previous, current)
+			if err == nil && len(changes) == 0 {
+				skipped = true
+				continue
+			}
+		}
+
+		// A diff against a removed version is re-encoded against the previous kept one
+		entry := entries[i]
+		if isDiff[i] && skipped && previousFull != nil {
+			rebased, err := rebaseDiffEntry(stored.(map[string]interface{}), previousFull, full)
+			if err != nil {
+				return 0, err
+			}
+			entry = rebased
+		}
+
+		kept = append(kept, entry)
+		previousFull = full
+		skipped = false
+		if !baseline {
+			previous = current
+		}
+	}
+
+	removed := len(entries) - len(kept)
+	if removed == 0 {
+		return 0, nil
+	}
+
+	// Restore most-recent-first order
+	for i, j := 0, len(kept)-1; i < j; i, j = i+1, j-1 {
+		kept[i], kept[j] = kept[j], kept[i]
+	}
+
+	if err := c.redisManager.ReplaceResourceHistory(resourceKey, kept); err != nil {
+		return 0, err
+	}
+	return removed, nil
+}
+
+// rebaseDiffEntry encodes a diff entry, whose object has been rebuilt to full, as a diff against base
+func rebaseDiffEntry(stored, base, full map[string]interface{}) (string, error) {
+	rebased := make(map[string]interface{}, len(stored))
+	for key, value := range stored {
+		rebased[key] = value
+	}
+	rebased["object"] = objectMetadata(full)
+	rebased["patch"] = createMergePatch(base, full)
+
+	data, err := json.Marshal(rebased)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal rebased entry: %w", err)
+	}
+	return string(data), nil
+}
+
+// normalizeForCompaction strips status and bookkeeping metadata so only meaningful changes remain
+func normalizeForCompaction(obj map[string]interface{}) map[string]interface{} {
+	normalized := CleanKubernetesObjectWithOptions(obj, CleanOptions{KeepStatus: false})
+	if metadata, ok := normalized["metadata"].(map[string]interface{}); ok {
+		for _, field := range compactionIgnoredMetadata {
+			delete(metadata, field)
+		}
+	}
+	return normalized
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ComparatorResult holds the kind-specific findings of a comparator
+type ComparatorResult struct {
+	Changes map[string]interface{} // named, kind-specific changes (e.g. "webhooks[foo].failurePolicy")
+	Alerts  []string               // changes that deserve prominent attention
+
+	ImageChanges  []ImageChange          // container image updates of a workload, see compareWorkloads
+	RouteChanges  []string               // human-readable route rule changes, see compareGRPCRoutes
+	StatusChanges map[string]interface{} // status condition changes (e.g. "status.conditions[Programmed]")
+}
+
+// KindComparator compares two versions of a resource of a specific kind.
+// It complements the generic labels/annotations/spec comparison in calculateChanges
+type KindComparator func(old, new *unstructured.Unstructured) *ComparatorResult
+
+// newComparatorResult creates an empty comparator result
+func newComparatorResult() *ComparatorResult {
+	return &ComparatorResult{
+		Changes:       make(map[string]interface{}),
+		Alerts:        make([]string, 0),
+		StatusChanges: make(map[string]interface{}),
+	}
+}
+
+// addChange records an old/new pair under the given name
+func (cr *ComparatorResult) addChange(name string, oldValue, newValue interface{}) {
+	cr.Changes[name] = map[string]interface{}{
+		"old": oldValue,
+		"new": newValue,
+	}
+}
+
+// defaultComparators returns the built-in comparators keyed by resource kind
+func defaultComparators() map[string]KindComparator {
+	return map[string]KindComparator{
+		"MutatingWebhookConfiguration":   compareWebhookConfigurations,
+		"ValidatingWebhookConfiguration": compareWebhookConfigurations,
+		"BackendTLSPolicy":               compareBackendTLSPolicies,
+		"GatewayClass":                   compareGatewayClasses,
+		"Gateway":                        compareGateways,
+		"HTTPRoute":                      compareHTTPRoutes,
+		"GRPCRoute":                      compareGRPCRoutes,
+		"Lease":                          compareLeases,
+		"ServiceAccount":                 compareServiceAccounts,
+		"ConfigMap":                      compareConfigData,
+		"Secret":                         compareConfigData,
+		"EnvoyProxy":                     compareEnvoyProxies,
+		"SecurityPolicy":                 compareSecurityPolicies,
+		"BackendTrafficPolicy":           compareBackendTrafficPolicies,
+		"Deployment":                     compareWorkloads,
+		"StatefulSet":                    compareWorkloads,
+		"DaemonSet":                      compareWorkloads,
+		"Job":                            compareWorkloads,
+		"CronJob":                        compareWorkloads,
+	}
+}
+
+// listItemsByName indexes a list of objects by their "name" field
+func listItemsByName(items []interface{}) (map[string]map[string]interface{}, []string) {
+	byName := make(map[string]map[string]interface{})
+	order := make([]string, 0, len(items))
+	for _, item := range items {
+		itemMap, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := itemMap["name"].(string)
+		if _, exists := byName[name]; !exists {
+			order = append(order, name)
+		}
+		byName[name] = itemMap
+	}
+	return byName, order
+}
@@ -4,8 +4,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/clientcmd"
 )
 
 // ResourceConfig defines what resources to watch
@@ -15,12 +20,27 @@ type ResourceConfig struct {
 	Resource   string   `json:"resource"`
 	Kind       string   `json:"kind"`
 	Enabled    bool     `json:"enabled"`
-	Namespaces []string `json:"namespaces"` // Array of namespaces to watch. Empty means all namespaces
+	Namespaces []string `json:"namespaces"` // Array of namespaces to watch. Empty means all namespaces; omitted means the config's top-level namespaces
+
+	LabelSelector string `json:"labelSelector,omitempty"` // Only watch resources matching this label selector. Empty means all
+	FieldSelector string `json:"fieldSelector,omitempty"` // Only watch resources matching this field selector, e.g. metadata.namespace!=kube-system
+
+	MetadataOnly bool `json:"metadataOnly,omitempty"` // Watch only object metadata (labels, annotations), not spec/status
+	WatchStatus  bool `json:"watchStatus,omitempty"`  // Also record status-only updates (e.g. Gateway Programmed/Accepted conditions)
+	FollowOwned  bool `json:"followOwned,omitempty"`  // Also watch the objects a workload owns (e.g. a Deployment's ReplicaSets and Pods), tagged with the workload
+
+	FullObjectEvery int `json:"fullObjectEvery,omitempty"` // Store the full object every K versions and metadata plus a diff for the rest (0 or 1 = always full)
+
+	RedactFields []string `json:"redactFields,omitempty"` // Dotted field paths replaced with a digest of their value before anything is stored or shown, e.g. spec.tls.privateKey
+
+	FocusFields map[string]string `json:"focusFields,omitempty"` // Summary fields for list views as name -> JSONPath, e.g. {"image": "{.spec.template.spec.containers[*].image}"}; replaces the kind's defaults
 }
 
 // WatcherConfig holds all resources to watch
 type WatcherConfig struct {
-	Resources []ResourceConfig `json:"resources"`
+	Namespaces []string         `json:"namespaces,omitempty"` // Default namespaces for resources that don't list their own. Empty means all namespaces
+	Namespace  string           `json:"namespace,omitempty"`  // Deprecated: single default namespace, read as namespaces: [namespace]
+	Resources  []ResourceConfig `json:"resources"`
 }
 
 // ToGVR converts ResourceConfig to GroupVersionResource
@@ -32,6 +52,25 @@ func (rc *ResourceConfig) ToGVR() schema.GroupVersionResource {
 	}
 }
 
+// ListOptions returns the options scoping both the List and Watch of this resource
+func (rc *ResourceConfig) ListOptions() metav1.ListOptions {
+	return metav1.ListOptions{
+		LabelSelector: rc.LabelSelector,
+		FieldSelector: rc.FieldSelector,
+	}
+}
+
+// Validate checks the resource's selectors so a malformed one fails at load time, not on first List
+func (rc *ResourceConfig) Validate() error {
+	if _, err := labels.Parse(rc.LabelSelector); err != nil {
+		return fmt.Errorf("invalid labelSelector for %s: %w", rc.Kind, err)
+	}
+	if _, err := fields.ParseSelector(rc.FieldSelector); err != nil {
+		return fmt.Errorf("invalid fieldSelector for %s: %w", rc.Kind, err)
+	}
+	return nil
+}
+
 // LoadConfigFromFile loads configuration from JSON file
 func LoadConfigFromFile(filepath string) (*WatcherConfig, error) {
 	file, err := os.ReadFile(filepath)
@@ -44,9 +83,35 @@ func LoadConfigFromFile(filepath string) (*WatcherConfig, error) {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
+	config.applyDefaultNamespaces()
+
+	for _, resource := range config.Resources {
+		if err := resource.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
 	return &config, nil
 }
 
+// applyDefaultNamespaces gives the top-level namespaces to resources that don't set "namespaces".
+// A resource with an explicit empty list keeps watching all namespaces
+func (wc *WatcherConfig) applyDefaultNamespaces() {
+	if len(wc.Namespaces) == 0 && wc.Namespace != "" {
+		wc.Namespaces = []string{wc.Namespace}
+	}
+	wc.Namespace = ""
+
+	if len(wc.Namespaces) == 0 {
+		return
+	}
+	for i := range wc.Resources {
+		if wc.Resources[i].Namespaces == nil {
+			wc.Resources[i].Namespaces = append([]string(nil), wc.Namespaces...)
+		}
+	}
+}
+
 // SaveConfigToFile saves configuration to JSON file
 func (wc *WatcherConfig) SaveConfigToFile(filepath string) error {
 	data, err := json.MarshalIndent(wc, "", "  ")
@@ -97,57 +162,213 @@ func (wc *WatcherConfig) AddResource(resource ResourceConfig) {
 	wc.Resources = append(wc.Resources, resource)
 }
 
-// GetDefaultWatcherConfig returns a default configuration (fallback)
-func GetDefaultWatcherConfig() *WatcherConfig {
+// inClusterNamespaceFile holds the pod's namespace when running in-cluster with a ServiceAccount
+const inClusterNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// kubeConfigLoadingRules returns kubeconfig loading rules honoring, in order:
+// an explicit path (--kubeconfig), the KUBECONFIG environment variable, then ~/.kube/config
+func kubeConfigLoadingRules(explicitPath string) *clientcmd.ClientConfigLoadingRules {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = explicitPath
+	return loadingRules
+}
+
+// ResolveDefaultNamespace picks the namespace to use when the config doesn't specify one.
+// Priority: 1) --namespace flag, 2) current kubeconfig context namespace,
+// 3) the pod's service account namespace when running in-cluster, 4) "default" (matches kubectl)
+func ResolveDefaultNamespace(kubeConfigPath string, flagNamespace string) string {
+	if flagNamespace != "" {
+		return flagNamespace
+	}
+
+	rawConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		kubeConfigLoadingRules(kubeConfigPath),
+		&clientcmd.ConfigOverrides{},
+	).RawConfig()
+	if err == nil {
+		if kubeContext, ok := rawConfig.Contexts[rawConfig.CurrentContext]; ok && kubeContext.Namespace != "" {
+			return kubeContext.Namespace
+		}
+	}
+
+	if namespace, err := os.ReadFile(inClusterNamespaceFile); err == nil && len(namespace) > 0 {
+		return strings.TrimSpace(string(namespace))
+	}
+
+	return "default"
+}
+
+// enabledResource is an enabled watch of a kind in the given namespaces (none = all)
+func enabledResource(gvr schema.GroupVersionResource, kind string, namespaces ...string) ResourceConfig {
+	return ResourceConfig{
+		Group:      gvr.Group,
+		Version:    gvr.Version,
+		Resource:   gvr.Resource,
+		Kind:       kind,
+		Enabled:    true,
+		Namespaces: namespaces,
+	}
+}
+
+// GetDefaultWatcherConfig returns a default configuration (fallback) watching defaultNamespace
+func GetDefaultWatcherConfig(defaultNamespace string) *WatcherConfig {
 	return &WatcherConfig{
 		Resources: []ResourceConfig{
+			// Cluster-scoped root of the Gateway API config chain; its Accepted condition is alerted on
+			{
+				Group:    "gateway.networking.k8s.io",
+				Version:  "v1",
+				Resource: "gatewayclasses",
+				Kind:     "GatewayClass",
+				Enabled:  true,
+			},
+			{
+				Group:       "gateway.networking.k8s.io",
+				Version:     "v1",
+				Resource:    "gateways",
+				Kind:        "Gateway",
+				Enabled:     true,
+				Namespaces:  []string{defaultNamespace},
+				WatchStatus: true, // Programmed/Accepted condition changes are alerted on
+			},
+			{
+				Group:       "gateway.networking.k8s.io",
+				Version:     "v1",
+				Resource:    "httproutes",
+				Kind:        "HTTPRoute",
+				Enabled:     true,
+				Namespaces:  []string{defaultNamespace},
+				WatchStatus: true, // Accepted/ResolvedRefs condition changes are alerted on
+			},
+			{
+				Group:       "gateway.networking.k8s.io",
+				Version:     "v1",
+				Resource:    "grpcroutes",
+				Kind:        "GRPCRoute",
+				Enabled:     true,
+				Namespaces:  []string{defaultNamespace},
+				WatchStatus: true, // Accepted/ResolvedRefs condition changes are alerted on
+			},
+			// Cross-namespace references a Gateway or route may make; a removed grant breaks them
 			{
 				Group:      "gateway.networking.k8s.io",
-				Version:    "v1",
-				Resource:   "gateways",
-				Kind:       "Gateway",
+				Version:    "v1beta1",
+				Resource:   "referencegrants",
+				Kind:       "ReferenceGrant",
 				Enabled:    true,
-				Namespaces: []string{"default"},
+				Namespaces: []string{defaultNamespace},
 			},
+			// Disabled by default: TCPRoute is only in the Gateway API experimental channel CRDs
 			{
 				Group:      "gateway.networking.k8s.io",
+				Version:    "v1alpha2",
+				Resource:   "tcproutes",
+				Kind:       "TCPRoute",
+				Enabled:    false,
+				Namespaces: []string{defaultNamespace},
+			},
+			{
+				Group:      "networking.k8s.io",
 				Version:    "v1",
-				Resource:   "httproutes",
-				Kind:       "HTTPRoute",
+				Resource:   "ingresses",
+				Kind:       "Ingress",
 				Enabled:    true,
-				Namespaces: []string{"default"},
+				Namespaces: []string{defaultNamespace},
 			},
+			enabledResource(EnvoyProxyGVR, "EnvoyProxy", defaultNamespace),
+			enabledResource(BackendTrafficPolicyGVR, "BackendTrafficPolicy", defaultNamespace),
+			enabledResource(SecurityPolicyGVR, "SecurityPolicy", defaultNamespace),
+			enabledResource(ClientTrafficPolicyGVR, "ClientTrafficPolicy", defaultNamespace),
+			// Disabled by default: BackendTLSPolicy requires Gateway API v1.4+ CRDs
 			{
-				Group:      "gateway.envoyproxy.io",
-				Version:    "v1alpha1",
-				Resource:   "envoyproxies",
-				Kind:       "EnvoyProxy",
-				Enabled:    true,
-				Namespaces: []string{"default"},
+				Group:      "gateway.networking.k8s.io",
+				Version:    "v1",
+				Resource:   "backendtlspolicies",
+				Kind:       "BackendTLSPolicy",
+				Enabled:    false,
+				Namespaces: []string{defaultNamespace},
 			},
+			// Disabled by default: ServiceAccount changes (secret references, token automount) for identity audits
 			{
-				Group:      "gateway.envoyproxy.io",
-				Version:    "v1alpha1",
-				Resource:   "backendtrafficpolicies",
-				Kind:       "BackendTrafficPolicy",
-				Enabled:    true,
-				Namespaces: []string{"default"},
+				Group:      "",
+				Version:    "v1",
+				Resource:   "serviceaccounts",
+				Kind:       "ServiceAccount",
+				Enabled:    false,
+				Namespaces: []string{defaultNamespace},
 			},
+			// Disabled by default: ConfigMap and Secret data, diffed per key (Secret values are always redacted)
 			{
-				Group:      "gateway.envoyproxy.io",
-				Version:    "v1alpha1",
-				Resource:   "securitypolicies",
-				Kind:       "SecurityPolicy",
-				Enabled:    true,
-				Namespaces: []string{"default"},
+				Group:      "",
+				Version:    "v1",
+				Resource:   "configmaps",
+				Kind:       "ConfigMap",
+				Enabled:    false,
+				Namespaces: []string{defaultNamespace},
 			},
 			{
-				Group:      "gateway.envoyproxy.io",
-				Version:    "v1alpha1",
-				Resource:   "clienttrafficpolicies",
-				Kind:       "ClientTrafficPolicy",
-				Enabled:    true,
-				Namespaces: []string{"default"},
+				Group:      "",
+				Version:    "v1",
+				Resource:   "secrets",
+				Kind:       "Secret",
+				Enabled:    false,
+				Namespaces: []string{defaultNamespace},
+			},
+			// Disabled by default: workloads, whose pod template image changes are reported (see followOwned for their pods)
+			{
+				Group:      "apps",
+				Version:    "v1",
+				Resource:   "deployments",
+				Kind:       "Deployment",
+				Enabled:    false,
+				Namespaces: []string{defaultNamespace},
+			},
+			{
+				Group:      "apps",
+				Version:    "v1",
+				Resource:   "statefulsets",
+				Kind:       "StatefulSet",
+				Enabled:    false,
+				Namespaces: []string{defaultNamespace},
+			},
+			{
+				Group:      "apps",
+				Version:    "v1",
+				Resource:   "daemonsets",
+				Kind:       "DaemonSet",
+				Enabled:    false,
+				Namespaces: []string{defaultNamespace},
+			},
+			{
+				Group:      "batch",
+				Version:    "v1",
+				Resource:   "jobs",
+				Kind:       "Job",
+				Enabled:    false,
+				Namespaces: []string{defaultNamespace},
+			},
+			{
+				Group:      "batch",
+				Version:    "v1",
+				Resource:   "cronjobs",
+				Kind:       "CronJob",
+				Enabled:    false,
+				Namespaces: []string{defaultNamespace},
+			},
+			// Cluster-scoped admission webhook configurations (no namespaces)
+			{
+				Group:    "admissionregistration.k8s.io",
+				Version:  "v1",
+				Resource: "mutatingwebhookconfigurations",
+				Kind:     "MutatingWebhookConfiguration",
+				Enabled:  true,
+			},
+			{
+				Group:    "admissionregistration.k8s.io",
+				Version:  "v1",
+				Resource: "validatingwebhookconfigurations",
+				Kind:     "ValidatingWebhookConfiguration",
+				Enabled:  true,
 			},
 		},
 	}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// compareConfigData reports the changes to a ConfigMap's or Secret's data per key, e.g.
+// "data[app.properties]", since their configuration lives outside spec. Secret values are
+// replaced by digests before they get here, so a changed Secret value shows as a changed digest
+func compareConfigData(old, new *unstructured.Unstructured) *ComparatorResult {
+	result := newComparatorResult()
+
+	for _, field := range []string{"data", "binaryData"} {
+		oldData, _, _ := unstructured.NestedFieldNoCopy(old.Object, field)
+		newData, _, _ := unstructured.NestedFieldNoCopy(new.Object, field)
+		oldMap, _ := oldData.(map[string]interface{})
+		newMap, _ := newData.(map[string]interface{})
+		if oldMap == nil {
+			oldMap = map[string]interface{}{}
+		}
+		if newMap == nil {
+			newMap = map[string]interface{}{}
+		}
+
+		changes, err := GetFieldChanges(oldMap, newMap)
+		if err != nil {
+			result.addChange(field, oldData, newData)
+			continue
+		}
+		for _, change := range changes {
+			result.Changes[fmt.Sprintf("%s[%s]", field, change.Path)] = map[string]interface{}{
+				"type": change.Type,
+				"old":  change.OldValue,
+				"new":  change.NewValue,
+			}
+		}
+	}
+
+	return result
+}
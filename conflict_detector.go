@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// fieldManagerConflictsMetric counts likely field-manager conflicts by kind
+var fieldManagerConflictsMetric = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "field_manager_conflicts_total",
+	Help: "Likely field-manager conflicts by kind.",
+}, []string{"kind"})
+
+// FieldManagerConflict describes two field managers overwriting each other's changes to the same fields
+type FieldManagerConflict struct {
+	Manager         string   // manager responsible for this change
+	PreviousManager string   // manager responsible for the previous change
+	Fields          []string // field paths changed by both
+}
+
+// String renders the conflict for logs
+func (c *FieldManagerConflict) String() string {
+	return fmt.Sprintf("%q and %q are both changing %v", c.Manager, c.PreviousManager, c.Fields)
+}
+
+// managerChange records which manager made a resource's last change and the fields it touched
+type managerChange struct {
+	manager string
+	fields  map[string]bool
+}
+
+// ConflictDetector flags likely server-side apply contention: successive changes to a resource
+// attributed to different field managers that touch the same field paths (two controllers
+// fighting over a field, making the object flap)
+type ConflictDetector struct {
+	lastChanges map[string]managerChange // resource key -> most recent change
+	mutex       sync.Mutex
+}
+
+// NewConflictDetector creates a new field-manager conflict detector
+func NewConflictDetector() *ConflictDetector {
+	return &ConflictDetector{
+		lastChanges: make(map[string]managerChange),
+	}
+}
+
+// Observe records a modification and returns the conflict it reveals, or nil.
+// The change is attributed to the managedFields entry with the most recent timestamp
+func (cd *ConflictDetector) Observe(key string, managedFields []metav1.ManagedFieldsEntry, old, new *unstructured.Unstructured) *FieldManagerConflict {
+	manager := latestFieldManager(managedFields)
+	if manager == "" {
+		return nil
+	}
+
+	fields := changedConfigFields(old, new)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	cd.mutex.Lock()
+	previous, seen := cd.lastChanges[key]
+	cd.lastChanges[key] = managerChange{manager: manager, fields: fields}
+	cd.mutex.Unlock()
+
+	if !seen || previous.manager == manager {
+		return nil
+	}
+
+	overlap := make([]string, 0)
+	for field := range fields {
+		if previous.fields[field] {
+			overlap = append(overlap, field)
+		}
+	}
+	if len(overlap) == 0 {
+		return nil
+	}
+	sort.Strings(overlap)
+
+	fieldManagerConflictsMetric.WithLabelValues(new.GetKind()).Inc()
+	return &FieldManagerConflict{
+		Manager:         manager,
+		PreviousManager: previous.manager,
+		Fields:          overlap,
+	}
+}
+
+// Forget drops the tracked history of a resource (e.g. once it is deleted)
+func (cd *ConflictDetector) Forget(key string) {
+	cd.mutex.Lock()
+	delete(cd.lastChanges, key)
+	cd.mutex.Unlock()
+}
+
+// latestFieldManager returns the manager of the most recently updated managedFields entry
+func latestFieldManager(managedFields []metav1.ManagedFieldsEntry) string {
+	var latest *metav1.ManagedFieldsEntry
+	for i := range managedFields {
+		entry := &managedFields[i]
+		if entry.Subresource != "" || entry.Time == nil {
+			continue // status updates don't contend for configuration fields
+		}
+		if latest == nil || entry.Time.After(latest.Time.Time) {
+			latest = entry
+		}
+	}
+	if latest == nil {
+		return ""
+	}
+	return latest.Manager
+}
+
+// changedConfigFields returns the full paths (e.g. spec.replicas) of the labels, annotations
+// and spec fields that differ between two versions
+func changedConfigFields(old, new *unstructured.Unstructured) map[string]bool {
+	oldFields := make(map[string]interface{})
+	newFields := make(map[string]interface{})
+	flattenFields("", configView(old), oldFields)
+	flattenFields("", configView(new), newFields)
+
+	changed := make(map[string]bool)
+	for path, value := range newFields {
+		if oldValue, ok := oldFields[path]; !ok || !reflect.DeepEqual(oldValue, value) {
+			changed[path] = true
+		}
+	}
+	for path := range oldFields {
+		if _, ok := newFields[path]; !ok {
+			changed[path] = true
+		}
+	}
+	return changed
+}
+
+// configView keeps only the fields field managers contend for: labels, annotations and spec
+func configView(obj *unstructured.Unstructured) map[string]interface{} {
+	return map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels":      obj.GetLabels(),
+			"annotations": obj.GetAnnotations(),
+		},
+		"spec": obj.Object["spec"],
+	}
+}
+
+// flattenFields collects every leaf value of a nested object keyed by its dotted path
+func flattenFields(prefix string, value interface{}, out map[string]interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			path := key
+			if prefix != "" {
+				path = prefix + "." + key
+			}
+			flattenFields(path, child, out)
+		}
+	case map[string]string:
+		for key, child := range v {
+			flattenFields(prefix+"."+key, child, out)
+		}
+	case []interface{}:
+		for i, child := range v {
+			flattenFields(fmt.Sprintf("%s[%d]", prefix, i), child, out)
+		}
+	case nil:
+		// absent fields have no leaves
+	default:
+		out[prefix] = v
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// correlationKeyPrefix prefixes the sorted sets indexing changes by correlation ID
+	correlationKeyPrefix = "correlation:"
+	// correlationTTL is how long a correlation index is kept after its last change
+	correlationTTL = 7 * 24 * time.Hour
+)
+
+// CorrelatedChange references one stored change belonging to a correlation group
+// (e.g. every resource touched by one CD run). The full version is available from /api/history
+type CorrelatedChange struct {
+	ResourceKey     string `json:"resource_key"`
+	Kind            string `json:"kind"`
+	Name            string `json:"name"`
+	Namespace       string `json:"namespace"`
+	Generation      int64  `json:"generation"`
+	ResourceVersion string `json:"resource_version"`
+	StoredTimestamp string `json:"stored_timestamp"`
+	ChangedBy       string `json:"changed_by,omitempty"`
+}
+
+// SetCorrelationAnnotation sets the annotation (e.g. deployment.kubernetes.io/revision or
+// example.com/change-id) whose value groups stored changes. Empty disables correlation
+func (rm *RedisManager) SetCorrelationAnnotation(annotation string) {
+	rm.correlationAnnotation = annotation
+}
+
+// correlationID returns the value of the correlation annotation on obj ("" if unset).
+// obj may be an *unstructured.Unstructured or a decoded JSON object
+func (rm *RedisManager) correlationID(obj interface{}) string {
+	if rm.correlationAnnotation == "" {
+		return ""
+	}
+
+	var u *unstructured.Unstructured
+	switch o := obj.(type) {
+	case *unstructured.Unstructured:
+		u = o
+	case map[string]interface{}:
+		u = &unstructured.Unstructured{Object: o}
+	default:
+		return ""
+	}
+	return u.GetAnnotations()[rm.correlationAnnotation]
+}
+
+// correlationKey returns the Redis key of a correlation index. The ID is escaped so it can
+// never contain "/" and be mistaken for a kind/name/namespace resource key
+func correlationKey(id string) string {
+	return correlationKeyPrefix + url.PathEscape(id)
+}
+
+// indexCorrelation adds a stored change to its correlation index, scored by store time
+func (rm *RedisManager) indexCorrelation(ctx context.Context, resourceKey string, storedObj StoredObject) error {
+	u, ok := storedObj.Object.(*unstructured.Unstructured)
+	if !ok {
+		u = &unstructured.Unstructured{}
+		if objMap, isMap := storedObj.Object.(map[string]interface{}); isMap {
+			u.Object = objMap
+		}
+	}
+
+	data, err := json.Marshal(CorrelatedChange{
+		ResourceKey:     resourceKey,
+		Kind:            u.GetKind(),
+		Name:            u.GetName(),
+		Namespace:       u.GetNamespace(),
+		Generation:      u.GetGeneration(),
+		ResourceVersion: u.GetResourceVersion(),
+		StoredTimestamp: storedObj.StoredTimestamp,
+		ChangedBy:       storedObj.ChangedBy,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal correlated change: %w", err)
+	}
+
+	key := correlationKey(storedObj.CorrelationID)
+	pipe := rm.client.TxPipeline()
+	pipe.ZAdd(ctx, key, &redis.Z{Score: float64(time.Now().UnixMilli()), Member: string(data)})
+	pipe.Expire(ctx, key, correlationTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to index correlation %s: %w", storedObj.CorrelationID, err)
+	}
+	return nil
+}
+
+// GetCorrelatedChanges returns every change indexed under a correlation ID, oldest first
+func (rm *RedisManager) GetCorrelatedChanges(id string) ([]CorrelatedChange, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	results, err := rm.client.ZRangeByScore(ctx, correlationKey(id), &redis.ZRangeBy{
+		Min: "-inf",
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get changes for correlation %s: %w", id, err)
+	}
+
+	changes := make([]CorrelatedChange, 0, len(results))
+	for _, result := range results {
+		var change CorrelatedChange
+		if err := json.Unmarshal([]byte(result), &change); err != nil {
+			continue // Skip invalid JSON
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
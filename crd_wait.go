@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/discovery"
+)
+
+// newServedPollBackoff returns the backoff between discovery checks for a resource that isn't
+// served yet: quick at first for a CRD being installed alongside the watcher, then every few minutes
+func newServedPollBackoff() *wait.Backoff {
+	return &wait.Backoff{
+		Duration: 5 * time.Second,
+		Factor:   2,
+		Jitter:   0.2,
+		Steps:    math.MaxInt32,
+		Cap:      5 * time.Minute,
+	}
+}
+
+// StartWhenServed waits (non-blocking) until the API server serves a resource, e.g. until its CRD
+// is installed, then starts watching it like Start. Late watches skip the startup List and
+// watch-start limiters, whose totals only cover the resources served at startup
+func (wm *WatcherManager) StartWhenServed(ctx context.Context, discoveryClient discovery.DiscoveryInterface, resource ResourceConfig) {
+	go func() {
+		backoff := newServedPollBackoff()
+		for {
+			if !sleepWithContext(ctx, backoff.Step()) {
+				return
+			}
+			served, err := IsResourceServed(discoveryClient, resource.ToGVR())
+			if err != nil {
+				logWarn(fmt.Sprintf("⚠️  Checking whether %s is served: %v", resource.Kind, err),
+					"failed to check whether resource is served", slog.String("kind", resource.Kind), slog.String("error", err.Error()))
+				continue
+			}
+			if served {
+				break
+			}
+		}
+
+		logInfo(fmt.Sprintf("🆕 %s (%s/%s/%s) is now served, starting watcher", resource.Kind, resource.Group, resource.Version, resource.Resource),
+			"resource now served, starting watcher", slog.String("kind", resource.Kind))
+		wm.mutex.Lock()
+		wm.late[resource.Kind] = true
+		wm.mutex.Unlock()
+		wm.Start(ctx, resource)
+	}()
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// dashboardHTML is the built-in dashboard: a single framework-free page browsing resources,
+// their history and diffs through /api/resources, /api/history and /api/diff
+//
+//go:embed dashboard/index.html
+var dashboardHTML []byte
+
+// handleDashboard serves the dashboard page at /ui
+func handleDashboard(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write(dashboardHTML)
+}
+
+// handleRoot redirects / to the dashboard; any other unregistered path is not found
+func handleRoot(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		writeErrorResponse(w, http.StatusNotFound, "Not found")
+		return
+	}
+	http.Redirect(w, r, "/ui", http.StatusFound)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// debouncedEventsMetric counts events coalesced into a later event for the same resource, by kind
+var debouncedEventsMetric = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "pipeline_debounced_events_total",
+	Help: "Events coalesced into a later event for the same resource, by kind.",
+}, []string{"kind"})
+
+// SetDebounceWindow enables coalescing of events for the same resource: the first event of a
+// resource opens a window, and only the last event received before it ends is processed.
+// Controllers updating a resource several times in a row then produce one version. Events are
+// delayed by up to the window, so keep it short (e.g. 1s). 0 disables it
+func (ep *EventPipeline) SetDebounceWindow(window time.Duration) {
+	ep.debounceWindow = window
+}
+
+// debounce holds a resource event until its window ends, replacing any event already held for
+// the resource. Returns false for events that aren't debounced (bookmarks, errors)
+func (ep *EventPipeline) debounce(event ResourceEvent) bool {
+	switch event.Type {
+	case EventTypeAdded, EventTypeModified, EventTypeDeleted:
+	default:
+		return false
+	}
+	key := fmt.Sprintf("%s/%s/%s", event.ResourceKind, event.Name, event.Namespace)
+
+	ep.stateMutex.Lock()
+	defer ep.stateMutex.Unlock()
+
+	if held, ok := ep.debounced[key]; ok {
+		// The resource is new to the pipeline if the first coalesced event added it
+		if held.Type == EventTypeAdded && event.Type == EventTypeModified {
+			event.Type = EventTypeAdded
+			event.RawType = held.RawType
+		}
+		event.Refresh = event.Refresh || held.Refresh
+		event.CoalescedCount = held.CoalescedCount + 1
+		event.FirstSeen = held.FirstSeen
+		ep.debounced[key] = event
+		debouncedEventsMetric.WithLabelValues(event.ResourceKind).Inc()
+		return true
+	}
+
+	event.CoalescedCount = 1
+	event.FirstSeen = event.Timestamp
+	ep.pending.Add(1)
+	ep.debounced[key] = event
+	time.AfterFunc(ep.debounceWindow, func() { ep.flushDebounced(key) })
+	return true
+}
+
+// flushDebounced sends the event held for a resource once its window has ended
+func (ep *EventPipeline) flushDebounced(key string) {
+	ep.stateMutex.Lock()
+	held, ok := ep.debounced[key]
+	delete(ep.debounced, key)
+	ep.stateMutex.Unlock()
+
+	if ok {
+		ep.eventChannel <- held
+	}
+}
+
+// coalescedSpan returns what a stored history entry records about the raw events debounced into
+// it: their number (1 when it stands for a single event) and when the first and last arrived
+func coalescedSpan(entry interface{}) (count int, from, until string) {
+	entryMap, ok := entry.(map[string]interface{})
+	if !ok {
+		return 1, "", ""
+	}
+	if value, ok := entryMap["coalesced_count"].(float64); ok && value > 1 {
+		count = int(value)
+	} else {
+		return 1, "", ""
+	}
+	from, _ = entryMap["coalesced_from"].(string)
+	until, _ = entryMap["coalesced_until"].(string)
+	return count, from, until
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// WatchActivity records when each watcher (kind and scope) last received an event
+type WatchActivity struct {
+	lastEvent map[string]time.Time
+	mutex     sync.Mutex
+}
+
+// NewWatchActivity creates an empty activity tracker
+func NewWatchActivity() *WatchActivity {
+	return &WatchActivity{lastEvent: make(map[string]time.Time)}
+}
+
+// Record notes that the watcher of kind in scope just received an event. Safe on nil
+func (wa *WatchActivity) Record(kind, scope string) {
+	if wa == nil {
+		return
+	}
+	wa.mutex.Lock()
+	wa.lastEvent[kind+" in "+scope] = time.Now()
+	wa.mutex.Unlock()
+}
+
+// Snapshot returns the last event time of every watcher that has received one
+func (wa *WatchActivity) Snapshot() map[string]time.Time {
+	snapshot := make(map[string]time.Time)
+	if wa == nil {
+		return snapshot
+	}
+	wa.mutex.Lock()
+	defer wa.mutex.Unlock()
+	for watcher, last := range wa.lastEvent {
+		snapshot[watcher] = last
+	}
+	return snapshot
+}
+
+// TrackedKeys returns the keys of every resource the pipeline holds a previous state for, sorted
+func (ep *EventPipeline) TrackedKeys() []string {
+	ep.stateMutex.RLock()
+	keys := make([]string, 0, len(ep.previousStates))
+	for key := range ep.previousStates {
+		keys = append(keys, key)
+	}
+	ep.stateMutex.RUnlock()
+
+	sort.Strings(keys)
+	return keys
+}
+
+// WriteDebugDump writes a diagnostic snapshot of the in-memory state: pipeline queue, tracked
+// resource keys and the last event time of each watcher
+func WriteDebugDump(w io.Writer, pipeline *EventPipeline, activity *WatchActivity) {
+	now := time.Now()
+	fmt.Fprintf(w, "===== DEBUG DUMP %s =====\n", formatDisplayTime(now))
+
+	fmt.Fprintf(w, "Pipeline queue: %d waiting\n", pipeline.QueueLength())
+
+	keys := pipeline.TrackedKeys()
+	fmt.Fprintf(w, "Tracked resources (%d):\n", len(keys))
+	for _, key := range keys {
+		fmt.Fprintf(w, "   %s\n", key)
+	}
+
+	lastEvents := activity.Snapshot()
+	watchers := make([]string, 0, len(lastEvents))
+	for watcher := range lastEvents {
+		watchers = append(watchers, watcher)
+	}
+	sort.Strings(watchers)
+	fmt.Fprintf(w, "Watcher last events (%d):\n", len(watchers))
+	for _, watcher := range watchers {
+		last := lastEvents[watcher]
+		fmt.Fprintf(w, "   %s: %s (%s ago)\n", watcher, formatDisplayTime(last), now.Sub(last).Round(time.Second))
+	}
+
+	fmt.Fprintln(w, "===== END DEBUG DUMP =====")
+}
+
+// writeDebugDumpTo writes the debug dump to path, or to stderr when path is empty
+func writeDebugDumpTo(path string, pipeline *EventPipeline, activity *WatchActivity) {
+	if path == "" {
+		WriteDebugDump(os.Stderr, pipeline, activity)
+		return
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		logWarn(fmt.Sprintf("⚠️  Failed to write debug dump: %v", err),
+			"failed to write debug dump", slog.String("path", path), slog.String("error", err.Error()))
+		return
+	}
+	defer file.Close()
+	WriteDebugDump(file, pipeline, activity)
+	logInfo(fmt.Sprintf("🩺 Debug dump written to %s", path), "debug dump written", slog.String("path", path))
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package main
+
+import (
+	"context"
+)
+
+// StartDebugDumpOnSignal is a no-op on platforms without SIGUSR1
+func StartDebugDumpOnSignal(ctx context.Context, path string, pipeline *EventPipeline, activity *WatchActivity) {
+}
@@ -0,0 +1,34 @@
+//go:build unix
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// StartDebugDumpOnSignal writes a debug dump (see WriteDebugDump) to path, or stderr when path is
+// empty, every time the process receives SIGUSR1, until ctx is cancelled. Processing continues
+// undisturbed
+func StartDebugDumpOnSignal(ctx context.Context, path string, pipeline *EventPipeline, activity *WatchActivity) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1)
+	logInfo(fmt.Sprintf("🩺 Send SIGUSR1 (kill -USR1 %d) for a debug dump of in-memory state", os.Getpid()),
+		"debug dump on SIGUSR1 enabled", slog.Int("pid", os.Getpid()))
+
+	go func() {
+		defer signal.Stop(signals)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-signals:
+				writeDebugDumpTo(path, pipeline, activity)
+			}
+		}
+	}()
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// DefaultDiffIgnorePaths are bookkeeping fields that change on every update, ignored by the
+// watcher's diffs unless --diff-ignore-paths says otherwise
+var DefaultDiffIgnorePaths = []string{"metadata.resourceVersion", "metadata.managedFields", "metadata.generation"}
+
+// diffIgnorePaths are the dotted paths the watcher leaves out of logged and stored diffs, see SetDiffIgnorePaths
+var diffIgnorePaths = DefaultDiffIgnorePaths
+
+// SetDiffIgnorePaths sets the dotted paths the watcher leaves out of its diffs (PrintDiff,
+// LogChanges and the field_changes of stored changes). Call it before the pipeline starts
+func SetDiffIgnorePaths(paths []string) {
+	diffIgnorePaths = paths
+}
+
+// DiffJSONWithOptions is DiffJSON with the given dotted paths (e.g. metadata.resourceVersion)
+// removed from both objects before diffing
+func DiffJSONWithOptions(old, new interface{}, ignorePaths []string) (*DiffResult, error) {
+	old, err := withoutPaths(old, ignorePaths)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare old object: %w", err)
+	}
+	new, err = withoutPaths(new, ignorePaths)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare new object: %w", err)
+	}
+	return DiffJSON(old, new)
+}
+
+// withoutPaths returns a JSON copy of obj with the dotted paths removed, or obj itself when
+// there are none. Objects that aren't JSON objects are returned as copied
+func withoutPaths(obj interface{}, paths []string) (interface{}, error) {
+	if len(paths) == 0 {
+		return obj, nil
+	}
+
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var copied interface{}
+	if err := json.Unmarshal(data, &copied); err != nil {
+		return nil, err
+	}
+
+	if objMap, ok := copied.(map[string]interface{}); ok {
+		for _, path := range paths {
+			unstructured.RemoveNestedField(objMap, strings.Split(path, ".")...)
+		}
+	}
+	return copied, nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/yudai/gojsondiff"
+)
+
+// diffSkippedMetric counts diffs abandoned for exceeding a DiffLimits bound, by reason (depth, deltas)
+var diffSkippedMetric = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "diff_skipped_total",
+	Help: "Diffs abandoned for exceeding --diff-max-depth or --diff-max-deltas, by reason.",
+}, []string{"reason"})
+
+// DiffSkippedMarker replaces the field changes of a change whose diff was abandoned
+const DiffSkippedMarker = "diff-skipped: too large"
+
+// ErrDiffTooLarge is returned (wrapped) by DiffJSON and GetFieldChanges when an input or the
+// resulting diff exceeds the configured DiffLimits
+var ErrDiffTooLarge = errors.New(DiffSkippedMarker)
+
+// DiffLimits bound the work of a single diff, guarding the pipeline against pathologically
+// deep or large objects. A zero limit is unbounded
+type DiffLimits struct {
+	MaxDepth  int // nesting depth of either object, checked before diffing
+	MaxDeltas int // changed fields in the diff
+}
+
+// diffLimits are the limits applied by every diff, see SetDiffLimits
+var diffLimits DiffLimits
+
+// SetDiffLimits sets the limits applied by every diff. Call it before the pipeline starts
+func SetDiffLimits(limits DiffLimits) {
+	diffLimits = limits
+}
+
+// checkDiffDepth rejects JSON documents nested deeper than the depth limit
+func checkDiffDepth(docs ...[]byte) error {
+	if diffLimits.MaxDepth <= 0 {
+		return nil
+	}
+	for _, doc := range docs {
+		if jsonDepthExceeds(doc, diffLimits.MaxDepth) {
+			diffSkippedMetric.WithLabelValues("depth").Inc()
+			return fmt.Errorf("%w: nested deeper than %d levels", ErrDiffTooLarge, diffLimits.MaxDepth)
+		}
+	}
+	return nil
+}
+
+// checkDiffDeltas rejects diffs with more changed fields than the delta limit
+func checkDiffDeltas(deltas []gojsondiff.Delta) error {
+	if diffLimits.MaxDeltas <= 0 {
+		return nil
+	}
+	if countDeltas(deltas, diffLimits.MaxDeltas) > diffLimits.MaxDeltas {
+		diffSkippedMetric.WithLabelValues("deltas").Inc()
+		return fmt.Errorf("%w: more than %d changed fields", ErrDiffTooLarge, diffLimits.MaxDeltas)
+	}
+	return nil
+}
+
+// jsonDepthExceeds reports whether a JSON document nests objects and arrays deeper than limit.
+// It scans the bytes without recursing, so it is safe on any input
+func jsonDepthExceeds(data []byte, limit int) bool {
+	depth := 0
+	inString, escaped := false, false
+	for _, c := range data {
+		switch {
+		case escaped:
+			escaped = false
+		case inString:
+			switch c {
+			case '\\':
+				escaped = true
+			case '"':
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == '{' || c == '[':
+			depth++
+			if depth > limit {
+				return true
+			}
+		case c == '}' || c == ']':
+			depth--
+		}
+	}
+	return false
+}
+
+// countDeltas counts the leaf changes of a diff, stopping once the count passes limit
+func countDeltas(deltas []gojsondiff.Delta, limit int) int {
+	count := 0
+	for _, delta := range deltas {
+		switch d := delta.(type) {
+		case *gojsondiff.Object:
+			count += countDeltas(d.Deltas, limit-count)
+		case *gojsondiff.Array:
+			count += countDeltas(d.Deltas, limit-count)
+		default:
+			count++
+		}
+		if count > limit {
+			break
+		}
+	}
+	return count
+}
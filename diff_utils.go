@@ -2,8 +2,11 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/yudai/gojsondiff"
 	"github.com/yudai/gojsondiff/formatter"
@@ -19,14 +22,32 @@ type DiffResult struct {
 
 // FieldChange represents a single field change
 type FieldChange struct {
-	Type     string
-	Path     string
-	OldValue interface{}
-	NewValue interface{}
+	Type     string      `json:"type"`
+	Path     string      `json:"path"`
+	OldValue interface{} `json:"old_value,omitempty"`
+	NewValue interface{} `json:"new_value,omitempty"`
 }
 
-// DiffJSON compares two JSON-serializable objects and returns the differences
+// DiffConfig controls how the ASCII diff of a DiffResult is rendered
+type DiffConfig struct {
+	Color          bool // ANSI colors, for terminals
+	ShowArrayIndex bool // prefix array elements with their index
+	MaxValueWidth  int  // added, removed and modified values longer than this are shown compact and truncated (0 = in full)
+}
+
+// DefaultDiffConfig returns the config used by DiffJSON: uncolored, with array indexes and full values
+func DefaultDiffConfig() DiffConfig {
+	return DiffConfig{ShowArrayIndex: true}
+}
+
+// DiffJSON compares two JSON-serializable objects and returns the differences.
+// The ASCII diff is uncolored so it can be returned by the API
 func DiffJSON(old, new interface{}) (*DiffResult, error) {
+	return DiffJSONWithConfig(old, new, DefaultDiffConfig())
+}
+
+// DiffJSONWithConfig is DiffJSON with control over how the ASCII diff is rendered
+func DiffJSONWithConfig(old, new interface{}, config DiffConfig) (*DiffResult, error) {
 	// Marshal to JSON
 	oldJSON, err := json.Marshal(old)
 	if err != nil {
@@ -37,6 +58,9 @@ func DiffJSON(old, new interface{}) (*DiffResult, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal new object: %w", err)
 	}
+	if err := checkDiffDepth(oldJSON, newJSON); err != nil {
+		return nil, err
+	}
 
 	// Create differ
 	differ := gojsondiff.New()
@@ -54,34 +78,40 @@ func DiffJSON(old, new interface{}) (*DiffResult, error) {
 
 	// Get deltas (list of changes)
 	deltas := diff.Deltas()
+	if err := checkDiffDeltas(deltas); err != nil {
+		return nil, err
+	}
 	deltaStrings := make([]string, 0, len(deltas))
 	for _, delta := range deltas {
 		deltaStrings = append(deltaStrings, fmt.Sprintf("%v", delta))
 	}
 
+	// Format as JSON diff (for programmatic use), before values are truncated for display
+	jsonFormatter := formatter.NewDeltaFormatter()
+	jsonDiff, err := jsonFormatter.Format(diff)
+	if err != nil {
+		jsonDiff = "{}"
+	}
+
 	// Format as ASCII diff
-	config := formatter.AsciiFormatterConfig{
-		ShowArrayIndex: true,
-		Coloring:       false,
+	if config.MaxValueWidth > 0 {
+		truncateDeltaValues(deltas, config.MaxValueWidth)
+	}
+	asciiConfig := formatter.AsciiFormatterConfig{
+		ShowArrayIndex: config.ShowArrayIndex,
+		Coloring:       config.Color,
 	}
 
 	// Unmarshal old JSON for formatter
 	var oldData interface{}
 	json.Unmarshal(oldJSON, &oldData)
 
-	asciiFormatter := formatter.NewAsciiFormatter(oldData, config)
+	asciiFormatter := formatter.NewAsciiFormatter(oldData, asciiConfig)
 	asciiDiff, err := asciiFormatter.Format(diff)
 	if err != nil {
 		asciiDiff = "Error formatting diff"
 	}
 
-	// Format as JSON diff (for programmatic use)
-	jsonFormatter := formatter.NewDeltaFormatter()
-	jsonDiff, err := jsonFormatter.Format(diff)
-	if err != nil {
-		jsonDiff = "{}"
-	}
-
 	return &DiffResult{
 		HasChanges: true,
 		Deltas:     deltaStrings,
@@ -90,9 +120,17 @@ func DiffJSON(old, new interface{}) (*DiffResult, error) {
 	}, nil
 }
 
-// PrintDiff prints a formatted diff with context
+// PrintDiff prints a formatted diff with context, colored when stdout is a terminal and NO_COLOR is unset.
+// Fields set with SetDiffIgnorePaths are left out
 func PrintDiff(label string, old, new interface{}) {
-	result, err := DiffJSON(old, new)
+	old, oldErr := withoutPaths(old, diffIgnorePaths)
+	new, newErr := withoutPaths(new, diffIgnorePaths)
+	if oldErr != nil || newErr != nil {
+		fmt.Printf("      ❌ Error comparing %s: %v\n", label, errors.Join(oldErr, newErr))
+		return
+	}
+
+	result, err := DiffJSONWithConfig(old, new, DiffConfig{Color: colorEnabled(), ShowArrayIndex: true, MaxValueWidth: diffValueWidth()})
 	if err != nil {
 		fmt.Printf("      ❌ Error comparing %s: %v\n", label, err)
 		return
@@ -115,8 +153,15 @@ func PrintDiff(label string, old, new interface{}) {
 	fmt.Println()
 }
 
-// LogChanges logs exact changes in a readable format
+// LogChanges logs exact changes in a readable format, leaving out fields set with SetDiffIgnorePaths
 func LogChanges(old, new interface{}, label string) {
+	old, oldErr := withoutPaths(old, diffIgnorePaths)
+	new, newErr := withoutPaths(new, diffIgnorePaths)
+	if oldErr != nil || newErr != nil {
+		fmt.Printf("Error comparing: %v\n", errors.Join(oldErr, newErr))
+		return
+	}
+
 	oldJSON, err := json.Marshal(old)
 	if err != nil {
 		fmt.Printf("Error marshaling old: %v\n", err)
@@ -149,7 +194,7 @@ func LogChanges(old, new interface{}, label string) {
 
 // logDeltasRecursive recursively logs all deltas with their actual values
 func logDeltasRecursive(deltas []gojsondiff.Delta, indent string) {
-	for i, delta := range deltas {
+	for i, delta := range sortedDeltas(deltas) {
 		path := ""
 
 		switch d := delta.(type) {
@@ -213,43 +258,128 @@ func logDeltasRecursive(deltas []gojsondiff.Delta, indent string) {
 	}
 }
 
-// formatValueCompact formats values in a compact readable way
+// sortedDeltas returns the deltas ordered by position (field name or array index)
+// so the same change always prints in the same order
+func sortedDeltas(deltas []gojsondiff.Delta) []gojsondiff.Delta {
+	sorted := make([]gojsondiff.Delta, len(deltas))
+	copy(sorted, deltas)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return deltaLess(sorted[i], sorted[j])
+	})
+	return sorted
+}
+
+// deltaLess is the total order of sortedDeltas: field names before array indexes before deltas
+// without a position, then by name or index, then by delta type, so an element removed and
+// another added at the same index always come out in the same order
+func deltaLess(a, b gojsondiff.Delta) bool {
+	rankA, nameA, indexA := positionKey(deltaPosition(a))
+	rankB, nameB, indexB := positionKey(deltaPosition(b))
+	if rankA != rankB {
+		return rankA < rankB
+	}
+	if nameA != nameB {
+		return nameA < nameB
+	}
+	if indexA != indexB {
+		return indexA < indexB
+	}
+	return fmt.Sprintf("%T", a) < fmt.Sprintf("%T", b)
+}
+
+// positionKey returns the sort rank of a position kind along with its name or index
+func positionKey(position gojsondiff.Position) (rank int, name string, index int) {
+	switch p := position.(type) {
+	case gojsondiff.Name:
+		return 0, string(p), 0
+	case gojsondiff.Index:
+		return 1, "", int(p)
+	}
+	return 2, "", 0
+}
+
+// deltaPosition returns the position of a delta in its parent object or array
+func deltaPosition(delta gojsondiff.Delta) gojsondiff.Position {
+	if postDelta, ok := delta.(gojsondiff.PostDelta); ok && postDelta.PostPosition() != nil {
+		return postDelta.PostPosition()
+	}
+	if preDelta, ok := delta.(gojsondiff.PreDelta); ok && preDelta.PrePosition() != nil {
+		return preDelta.PrePosition()
+	}
+	return nil
+}
+
+// formatValueCompact formats values in a compact readable way, truncated to fit the terminal
 func formatValueCompact(val interface{}) string {
+	return formatValueWidth(val, diffValueWidth())
+}
+
+// formatValueWidth formats values in a compact readable way, truncated to width characters
+func formatValueWidth(val interface{}, width int) string {
 	if val == nil {
 		return "<nil>"
 	}
 
 	switch v := val.(type) {
 	case string:
-		if len(v) > 100 {
-			return fmt.Sprintf(`"%s..."`, v[:100])
-		}
-		return fmt.Sprintf(`"%s"`, v)
+		return fmt.Sprintf(`"%s"`, truncate(v, width))
 	case bool, float64, int:
 		return fmt.Sprintf("%v", v)
 	case map[string]interface{}, []interface{}:
 		jsonBytes, err := json.Marshal(v)
 		if err != nil {
-			return fmt.Sprintf("%v", val)
-		}
-		jsonStr := string(jsonBytes)
-		if len(jsonStr) > 100 {
-			return jsonStr[:100] + "..."
+			return truncate(fmt.Sprintf("%v", val), width)
 		}
-		return jsonStr
+		return truncate(string(jsonBytes), width)
 	default:
-		str := fmt.Sprintf("%v", val)
-		if len(str) > 100 {
-			return str[:100] + "..."
+		return truncate(fmt.Sprintf("%v", val), width)
+	}
+}
+
+// truncateDeltaValues replaces the added, removed and modified values of deltas that are longer
+// than width with their compact form, truncated like formatValueCompact
+func truncateDeltaValues(deltas []gojsondiff.Delta, width int) {
+	for _, delta := range deltas {
+		switch d := delta.(type) {
+		case *gojsondiff.Object:
+			truncateDeltaValues(d.Deltas, width)
+		case *gojsondiff.Array:
+			truncateDeltaValues(d.Deltas, width)
+		case *gojsondiff.Added:
+			d.Value = truncatedValue(d.Value, width)
+		case *gojsondiff.Deleted:
+			d.Value = truncatedValue(d.Value, width)
+		case *gojsondiff.Modified:
+			d.OldValue = truncatedValue(d.OldValue, width)
+			d.NewValue = truncatedValue(d.NewValue, width)
+		case *gojsondiff.TextDiff:
+			d.OldValue = truncatedValue(d.OldValue, width)
+			d.NewValue = truncatedValue(d.NewValue, width)
+		}
+	}
+}
+
+// truncatedValue returns value, or a string with its compact JSON truncated to width when longer
+func truncatedValue(value interface{}, width int) interface{} {
+	switch v := value.(type) {
+	case string:
+		return truncate(v, width)
+	case map[string]interface{}, []interface{}:
+		data, err := json.Marshal(v)
+		if err == nil && utf8.RuneCount(data) > width {
+			return truncate(string(data), width)
 		}
-		return str
 	}
+	return value
 }
 
 // GetFieldChanges extracts individual field changes with their paths
 func GetFieldChanges(old, new interface{}) ([]FieldChange, error) {
 	oldJSON, _ := json.Marshal(old)
 	newJSON, _ := json.Marshal(new)
+	if err := checkDiffDepth(oldJSON, newJSON); err != nil {
+		return nil, err
+	}
 
 	differ := gojsondiff.New()
 	diff, err := differ.Compare(oldJSON, newJSON)
@@ -263,33 +393,39 @@ func GetFieldChanges(old, new interface{}) ([]FieldChange, error) {
 
 	changes := make([]FieldChange, 0)
 	deltas := diff.Deltas()
-	changes = extractChangesRecursive(deltas, changes)
+	if err := checkDiffDeltas(deltas); err != nil {
+		return nil, err
+	}
+	changes = extractChangesRecursive(deltas, "", changes)
 
 	return changes, nil
 }
 
-// extractChangesRecursive recursively extracts all changes from deltas
-func extractChangesRecursive(deltas []gojsondiff.Delta, changes []FieldChange) []FieldChange {
-	for _, delta := range deltas {
+// extractChangesRecursive recursively extracts all changes from deltas, with paths relative to
+// prefix (e.g. spec.hostnames[0])
+func extractChangesRecursive(deltas []gojsondiff.Delta, prefix string, changes []FieldChange) []FieldChange {
+	for _, delta := range sortedDeltas(deltas) {
 		var change FieldChange
 
 		// Get the path
 		if postDelta, ok := delta.(gojsondiff.PostDelta); ok && postDelta.PostPosition() != nil {
-			change.Path = postDelta.PostPosition().String()
+			change.Path = joinFieldPath(prefix, postDelta.PostPosition())
 		} else if preDelta, ok := delta.(gojsondiff.PreDelta); ok && preDelta.PrePosition() != nil {
-			change.Path = preDelta.PrePosition().String()
+			change.Path = joinFieldPath(prefix, preDelta.PrePosition())
+		} else {
+			change.Path = prefix
 		}
 
 		// Determine the type and values based on delta type
 		switch d := delta.(type) {
 		case *gojsondiff.Object:
 			// Recursively process object's nested deltas
-			changes = extractChangesRecursive(d.Deltas, changes)
+			changes = extractChangesRecursive(d.Deltas, change.Path, changes)
 			continue
 
 		case *gojsondiff.Array:
 			// Recursively process array's nested deltas
-			changes = extractChangesRecursive(d.Deltas, changes)
+			changes = extractChangesRecursive(d.Deltas, change.Path, changes)
 			continue
 
 		case *gojsondiff.Added:
@@ -325,6 +461,17 @@ func extractChangesRecursive(deltas []gojsondiff.Delta, changes []FieldChange) [
 	return changes
 }
 
+// joinFieldPath appends a diff position to a field path: names as .name, array indexes as [i]
+func joinFieldPath(prefix string, position gojsondiff.Position) string {
+	if index, ok := position.(gojsondiff.Index); ok {
+		return fmt.Sprintf("%s[%d]", prefix, int(index))
+	}
+	if prefix == "" {
+		return position.String()
+	}
+	return prefix + "." + position.String()
+}
+
 // PrintFieldChanges prints individual field changes in a readable format
 func PrintFieldChanges(changes []FieldChange) {
 	if len(changes) == 0 {
@@ -374,3 +521,56 @@ func formatValue(val interface{}) string {
 		return fmt.Sprintf("%v", val)
 	}
 }
+
+// FormatChangesMarkdown renders field changes as GitHub-flavored markdown: a summary table
+// of changed fields followed by a collapsible block with the full values, ready to paste into PRs and docs
+func FormatChangesMarkdown(changes []FieldChange) string {
+	if len(changes) == 0 {
+		return "_No changes detected_\n"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("| Field | Old | New |\n")
+	sb.WriteString("| --- | --- | --- |\n")
+	for _, change := range changes {
+		sb.WriteString(fmt.Sprintf("| `%s` | %s | %s |\n",
+			change.Path, markdownCell(change.OldValue), markdownCell(change.NewValue)))
+	}
+
+	sb.WriteString(fmt.Sprintf("\n<details>\n<summary>Full diff (%d changes)</summary>\n\n```diff\n", len(changes)))
+	for _, change := range changes {
+		sb.WriteString(fmt.Sprintf("@@ %s (%s) @@\n", change.Path, change.Type))
+		if change.OldValue != nil {
+			writeDiffLines(&sb, "-", change.OldValue)
+		}
+		if change.NewValue != nil {
+			writeDiffLines(&sb, "+", change.NewValue)
+		}
+	}
+	sb.WriteString("```\n\n</details>\n")
+
+	return sb.String()
+}
+
+// markdownCell formats a value for a markdown table cell, escaping characters that would break the table
+func markdownCell(val interface{}) string {
+	if val == nil {
+		return "_(not set)_"
+	}
+	cell := formatValueWidth(val, apiDiffValueWidth)
+	cell = strings.ReplaceAll(cell, "|", `\|`)
+	cell = strings.ReplaceAll(cell, "`", "'")
+	cell = strings.ReplaceAll(cell, "\n", " ")
+	return "`" + cell + "`"
+}
+
+// writeDiffLines writes a value as indented JSON with every line prefixed by the diff marker
+func writeDiffLines(sb *strings.Builder, marker string, val interface{}) {
+	valueJSON, err := json.MarshalIndent(val, "", "  ")
+	if err != nil {
+		valueJSON = []byte(fmt.Sprintf("%v", val))
+	}
+	for _, line := range strings.Split(string(valueJSON), "\n") {
+		sb.WriteString(marker + " " + line + "\n")
+	}
+}
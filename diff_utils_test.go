@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/yudai/gojsondiff"
+)
+
+// fieldChangesFixture returns two objects whose diff mixes added, removed and modified fields
+// across nested maps and arrays
+func fieldChangesFixture() (old, new map[string]interface{}) {
+	old = map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": map[string]interface{}{"app": "web", "tier": "frontend", "team": "a"},
+		},
+		"spec": map[string]interface{}{
+			"replicas":  float64(1),
+			"hostnames": []interface{}{"a.example.com", "b.example.com"},
+			"zone":      "eu-1",
+			"paused":    false,
+		},
+	}
+	new = map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": map[string]interface{}{"app": "web-v2", "tier": "backend", "owner": "b"},
+		},
+		"spec": map[string]interface{}{
+			"replicas":  float64(3),
+			"hostnames": []interface{}{"c.example.com"},
+			"paused":    true,
+			"region":    "eu",
+		},
+	}
+	return old, new
+}
+
+func TestGetFieldChangesOrderIsStable(t *testing.T) {
+	old, new := fieldChangesFixture()
+
+	golden := []string{
+		"MODIFIED metadata.labels.app",
+		"ADDED metadata.labels.owner",
+		"REMOVED metadata.labels.team",
+		"MODIFIED metadata.labels.tier",
+		"REMOVED spec.hostnames[0]",
+		"MODIFIED spec.hostnames[0]",
+		"MODIFIED spec.paused",
+		"ADDED spec.region",
+		"MODIFIED spec.replicas",
+		"REMOVED spec.zone",
+	}
+
+	// Object deltas come from map iteration, so a single run could pass by luck
+	for run := 0; run < 50; run++ {
+		changes, err := GetFieldChanges(old, new)
+		if err != nil {
+			t.Fatalf("GetFieldChanges: %v", err)
+		}
+		got := make([]string, 0, len(changes))
+		for _, change := range changes {
+			got = append(got, change.Type+" "+change.Path)
+		}
+		if !reflect.DeepEqual(got, golden) {
+			t.Fatalf("run %d: changes = %q, want %q", run, got, golden)
+		}
+	}
+}
+
+func TestSortedDeltasIsATotalOrder(t *testing.T) {
+	deltas := []gojsondiff.Delta{
+		gojsondiff.NewAdded(gojsondiff.Index(1), "added"),
+		gojsondiff.NewDeleted(gojsondiff.Index(1), "deleted"),
+		gojsondiff.NewModified(gojsondiff.Index(0), "old", "new"),
+		gojsondiff.NewModified(gojsondiff.Name("b"), "old", "new"),
+		gojsondiff.NewAdded(gojsondiff.Name("a"), "added"),
+		gojsondiff.NewObject(gojsondiff.Name("c"), nil),
+	}
+	want := []gojsondiff.Delta{deltas[4], deltas[3], deltas[5], deltas[2], deltas[0], deltas[1]}
+
+	// Mixed names and indexes must sort the same whatever order they arrive in
+	shuffled := make([]gojsondiff.Delta, len(deltas))
+	for run := 0; run < 50; run++ {
+		copy(shuffled, deltas)
+		rand.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+
+		got := sortedDeltas(shuffled)
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("run %d: position %d is %#v, want %#v", run, i, got[i], want[i])
+			}
+		}
+	}
+}
+
+var updateGolden = flag.Bool("update", false, "rewrite testdata/*.golden with the current output")
+
+// assertGolden compares got with testdata/<name>, rewriting the file when -update is set
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v (run go test -update to create it)", path, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s (run go test -update to accept)\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+// captureStdout returns everything fn prints to os.Stdout
+func captureStdout(t *testing.T, fn func()) []byte {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	done := make(chan []byte)
+	go func() {
+		out, _ := io.ReadAll(r)
+		done <- out
+	}()
+	fn()
+	w.Close()
+	return <-done
+}
+
+func TestFieldChangesRenderingMatchesGolden(t *testing.T) {
+	changes, err := GetFieldChanges(fieldChangesFixture())
+	if err != nil {
+		t.Fatalf("GetFieldChanges: %v", err)
+	}
+
+	assertGolden(t, "field_changes.golden", captureStdout(t, func() { PrintFieldChanges(changes) }))
+	assertGolden(t, "field_changes_markdown.golden", []byte(FormatChangesMarkdown(changes)))
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// displayLayout is how timestamps are shown to humans, with the zone abbreviation
+const displayLayout = "2006-01-02 15:04:05 MST"
+
+// displayLocation is the timezone human-readable output is shown in, see SetDisplayTimezone
+var displayLocation = time.UTC
+
+// SetDisplayTimezone sets the IANA timezone (e.g. Europe/Berlin) timestamps are shown in by the
+// human-readable logs and the query CLI. Stored timestamps stay UTC. Empty means UTC
+func SetDisplayTimezone(name string) error {
+	location, err := loadTimezone(name)
+	if err != nil {
+		return err
+	}
+	displayLocation = location
+	return nil
+}
+
+// loadTimezone resolves an IANA timezone name, "" being UTC
+func loadTimezone(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q: %w", name, err)
+	}
+	return location, nil
+}
+
+// formatDisplayTime formats a timestamp for human-readable output in the display timezone
+func formatDisplayTime(t time.Time) string {
+	return t.In(displayLocation).Format(displayLayout)
+}
+
+// requestTimezone returns the timezone requested with ?tz= (UTC when absent)
+func requestTimezone(r *http.Request) (*time.Location, error) {
+	return loadTimezone(r.URL.Query().Get("tz"))
+}
+
+// inTimezone rewrites an RFC3339 timestamp with the offset of location. Timestamps that don't
+// parse are returned unchanged
+func inTimezone(timestamp string, location *time.Location) string {
+	if location == time.UTC {
+		return timestamp
+	}
+	t, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return timestamp
+	}
+	return t.In(location).Format(time.RFC3339Nano)
+}
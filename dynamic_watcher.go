@@ -3,133 +3,352 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"math"
+	"sync"
 	"time"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/metadata"
 )
 
+// WatchOptions carries shared watcher dependencies that apply across all resources
+type WatchOptions struct {
+	ListLimiter  *ListLimiter          // bounds parallel initial List calls (nil = unbounded)
+	Breaker      *CircuitBreaker       // shared Kubernetes API circuit breaker (nil = disabled)
+	Checkpoints  *ResourceVersionStore // persists last-seen resourceVersions to resume after restart (nil = disabled)
+	Timeout      time.Duration         // stops each resource's watchers after this long (0 = run until ctx is cancelled)
+	PageSize     int64                 // objects per page of the initial List (0 = unpaginated)
+	StartLimiter *WatchStartLimiter    // bounds how many watches are coming online at once (nil = unbounded)
+	Activity     *WatchActivity        // records each watcher's last event time (nil = disabled)
+
+	MetadataClient metadata.Interface // client for metadata-only watches
+	MetadataOnly   bool               // watch PartialObjectMetadata via MetadataClient instead of full objects
+
+	UseInformers   bool          // watch through client-go shared informers, see WatchResourceWithInformer (not for MetadataOnly)
+	InformerResync time.Duration // how often informers replay their cache as updates (0 = never)
+}
+
 // WatchResource is a generic watcher for any Kubernetes resource using dynamic client
-// If namespaces is empty, watches across all namespaces
+// If namespaces is empty, watches across all namespaces. listOptions scopes both the initial List
+// and the Watch (e.g. a label selector; empty matches everything). Blocks until ctx is cancelled, opts.Timeout
+// elapses, or every namespace's watcher has failed permanently; returns the permanent failures
+// (nil on shutdown or timeout)
 func WatchResource(
+	ctx context.Context,
 	dynamicClient dynamic.Interface,
 	gvr schema.GroupVersionResource,
 	namespaces []string,
 	kind string,
+	listOptions metav1.ListOptions,
 	pipeline *EventPipeline,
-) {
+	opts WatchOptions,
+) error {
+	if opts.UseInformers && !opts.MetadataOnly {
+		return WatchResourceWithInformer(ctx, dynamicClient, gvr, namespaces, kind, listOptions, pipeline, opts)
+	}
+
+	// A timeout stops the watchers the same way shutdown does
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
 	// If no namespaces specified, watch all namespaces
 	if len(namespaces) == 0 {
-		watchAllNamespaces(dynamicClient, gvr, kind, pipeline)
-		return
+		return watchAllNamespaces(ctx, dynamicClient, gvr, kind, listOptions, pipeline, opts)
 	}
 
-	// Watch each specified namespace
-	for _, namespace := range namespaces {
-		go watchNamespace(dynamicClient, gvr, namespace, kind, pipeline)
+	// Watch each specified namespace; one namespace failing doesn't stop the others
+	errs := make([]error, len(namespaces))
+	var wg sync.WaitGroup
+	for i, namespace := range namespaces {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = watchNamespace(ctx, dynamicClient, gvr, namespace, kind, listOptions, pipeline, opts)
+		}()
 	}
+	wg.Wait()
+
+	return errors.Join(errs...)
 }
 
 // watchNamespace watches resources in a specific namespace
 func watchNamespace(
+	ctx context.Context,
+	dynamicClient dynamic.Interface,
+	gvr schema.GroupVersionResource,
+	namespace string,
+	kind string,
+	listOptions metav1.ListOptions,
+	pipeline *EventPipeline,
+	opts WatchOptions,
+) error {
+	return watchScope(ctx, dynamicClient, gvr, namespace, "namespace "+namespace, kind, listOptions, pipeline, opts)
+}
+
+// watchAllNamespaces watches resources across all namespaces
+func watchAllNamespaces(
+	ctx context.Context,
+	dynamicClient dynamic.Interface,
+	gvr schema.GroupVersionResource,
+	kind string,
+	listOptions metav1.ListOptions,
+	pipeline *EventPipeline,
+	opts WatchOptions,
+) error {
+	return watchScope(ctx, dynamicClient, gvr, metav1.NamespaceAll, "all namespaces", kind, listOptions, pipeline, opts)
+}
+
+// watchScope lists existing resources and then watches for changes in one namespace
+// (or across all namespaces when namespace is empty). scope is used for log messages.
+// If a resourceVersion checkpoint exists, the List is skipped and the watch resumes from it;
+// an expired checkpoint (410 Gone) falls back to a fresh List. Closed or failed watches are
+// re-established from the last seen resourceVersion with exponential backoff.
+// Returns nil when ctx is cancelled, or the error that made it give up (e.g. the resource is no longer served)
+func watchScope(
+	ctx context.Context,
 	dynamicClient dynamic.Interface,
 	gvr schema.GroupVersionResource,
 	namespace string,
+	scope string,
 	kind string,
+	listOptions metav1.ListOptions,
 	pipeline *EventPipeline,
-) {
+	opts WatchOptions,
+) error {
+	// Hold a start slot until the watch is established
+	if !opts.StartLimiter.Acquire(ctx) {
+		return nil
+	}
+	online := false
+	defer func() {
+		if !online {
+			opts.StartLimiter.Abandon()
+		}
+	}()
+
 	resourceName := gvr.Resource
+	client := newResourceClient(dynamicClient, gvr, namespace, kind, opts)
+	checkpointKey := CheckpointKey(gvr, namespace)
+
+	resourceVersion := opts.Checkpoints.Load(checkpointKey)
+	if resourceVersion != "" {
+		logInfo(fmt.Sprintf("⏩ Resuming %s in %s from resourceVersion %s", kind, scope, resourceVersion),
+			"resuming watch", append(watchAttrs(kind, scope), slog.String("resource_version", resourceVersion))...)
+		opts.ListLimiter.Skip(kind, scope)
+	} else {
+		// The live watch below only starts once this List has completed
+		resourceVersion = listExisting(ctx, client, kind, scope, listOptions, pipeline, opts, opts.ListLimiter)
+	}
+
+	backoff := newWatchBackoff()
+	for {
+		// Now start watching for changes
+		var watcher watch.Interface
+		err := opts.Breaker.Do(ctx, func() error {
+			var watchErr error
+			watcher, watchErr = client.Watch(
+				ctx,
+				watchListOptions(listOptions, resourceVersion),
+			)
+			return watchErr
+		})
+		if ctx.Err() != nil {
+			logInfo(fmt.Sprintf("🛑 Stopped watching %s in %s", kind, scope), "stopped watching", watchAttrs(kind, scope)...)
+			return nil
+		}
+		if err != nil && isResourceVersionExpired(err) {
+			logWarn(fmt.Sprintf("⚠️  resourceVersion %s for %s in %s expired (410 Gone), re-listing", resourceVersion, kind, scope),
+				"resourceVersion expired, re-listing", append(watchAttrs(kind, scope), slog.String("resource_version", resourceVersion))...)
+			resourceVersion = listExisting(ctx, client, kind, scope, listOptions, pipeline, opts, nil)
+			continue
+		}
+		if err != nil && isPermanentWatchError(err) {
+			logError(fmt.Sprintf("❌ Giving up watching %s in %s: %v", resourceName, scope, err),
+				"giving up watching", append(watchAttrs(kind, scope), slog.String("error", err.Error()))...)
+			return fmt.Errorf("watch %s in %s: %w", resourceName, scope, err)
+		}
+		if err != nil {
+			delay := backoff.Step()
+			logWarn(fmt.Sprintf("⚠️  Failed to watch %s in %s: %v (retrying in %s)", resourceName, scope, err, delay.Round(time.Millisecond)),
+				"failed to watch, retrying", append(watchAttrs(kind, scope), slog.String("error", err.Error()), slog.Duration("retry_in", delay))...)
+			if !sleepWithContext(ctx, delay) {
+				return nil
+			}
+			continue
+		}
+
+		logInfo(fmt.Sprintf("✅ Watching %s in %s for changes", kind, scope), "watching", watchAttrs(kind, scope)...)
+		if !online {
+			online = true
+			opts.StartLimiter.Online(kind, scope)
+		}
+		watchStarted := time.Now()
+
+		expired := false
+		reconnect := false
+		for event := range watcher.ResultChan() {
+			opts.Activity.Record(kind, scope)
+			if event.Type == watch.Error {
+				watchErr := apierrors.FromObject(event.Object)
+				pipeline.SendEvent(ResourceEvent{
+					Type:         EventTypeError,
+					RawType:      event.Type,
+					ResourceKind: kind,
+					Object:       watchErr,
+					Timestamp:    time.Now(),
+				})
+				expired = isResourceVersionExpired(watchErr)
+				reconnect = true
+				break
+			}
 
-	// First, list existing resources
-	fmt.Printf("📋 Listing existing %s in namespace %s...\n", kind, namespace)
-	existingResources, err := dynamicClient.Resource(gvr).Namespace(namespace).List(
-		context.TODO(),
-		metav1.ListOptions{},
-	)
+			received, ok := event.Object.(*unstructured.Unstructured)
+			if !ok {
+				reportCastFailure(&UnexpectedObjectError{
+					Kind:     kind,
+					Scope:    scope,
+					Context:  fmt.Sprintf("%s event", event.Type),
+					Expected: "*unstructured.Unstructured",
+					Object:   event.Object,
+				})
+				continue
+			}
+			obj := redactObject(received)
+			resourceVersion = obj.GetResourceVersion()
+			opts.Checkpoints.Record(checkpointKey, resourceVersion)
 
-	if err == nil && len(existingResources.Items) > 0 {
-		for _, resource := range existingResources.Items {
-			fmt.Printf("   Found existing %s: %s/%s\n",
-				kind, resource.GetNamespace(), resource.GetName())
+			if event.Type == watch.Bookmark {
+				// Bookmarks only carry a resourceVersion; they advance progress but aren't changes
+				pipeline.SendEvent(ResourceEvent{
+					Type:         EventTypeBookmark,
+					RawType:      event.Type,
+					ResourceKind: kind,
+					Object:       obj,
+					Timestamp:    time.Now(),
+				})
+				continue
+			}
 
-			resourceCopy := resource.DeepCopy()
+			// Debug: Log the complete object in JSON format, except for kinds with redacted fields
+			if !structuredLogging() && !hasRedactedFields(kind) {
+				objJSON, _ := json.MarshalIndent(obj.Object, "", "  ")
+				fmt.Printf("\n🔍 FULL OBJECT RECEIVED (%s):\n%s\n\n", scope, string(objJSON))
+			}
+
+			// Send to pipeline
 			pipeline.SendEvent(ResourceEvent{
-				Type:          EventTypeAdded,
+				Type:          EventTypeFromWatch(event.Type),
+				RawType:       event.Type,
 				ResourceKind:  kind,
-				Namespace:     resourceCopy.GetNamespace(),
-				Name:          resourceCopy.GetName(),
-				Object:        resourceCopy,
+				Namespace:     obj.GetNamespace(),
+				Name:          obj.GetName(),
+				Object:        obj,
 				Timestamp:     time.Now(),
-				ManagedFields: resourceCopy.GetManagedFields(),
+				ManagedFields: obj.GetManagedFields(),
 			})
 		}
-	} else if err != nil {
-		fmt.Printf("   ⚠️  Could not list %s: %v\n", resourceName, err)
-	}
-
-	// Now start watching for changes
-	watcher, err := dynamicClient.Resource(gvr).Namespace(namespace).Watch(
-		context.TODO(),
-		metav1.ListOptions{},
-	)
-	if err != nil {
-		fmt.Printf("⚠️  Failed to watch %s in namespace %s: %v\n", resourceName, namespace, err)
-		return
-	}
-	defer watcher.Stop()
+		watcher.Stop()
 
-	fmt.Printf("✅ Watching %s in namespace %s for changes\n", kind, namespace)
-
-	events := watcher.ResultChan()
+		// Cancelling ctx aborts the watch request, which closes the result channel
+		if ctx.Err() != nil {
+			logInfo(fmt.Sprintf("🛑 Stopped watching %s in %s", kind, scope), "stopped watching", watchAttrs(kind, scope)...)
+			return nil
+		}
 
-	for event := range events {
-		obj, ok := event.Object.(*unstructured.Unstructured)
-		if !ok {
+		if expired {
+			logWarn(fmt.Sprintf("⚠️  Watch for %s in %s expired (410 Gone), re-listing", kind, scope),
+				"watch expired, re-listing", watchAttrs(kind, scope)...)
+			resourceVersion = listExisting(ctx, client, kind, scope, listOptions, pipeline, opts, nil)
 			continue
 		}
 
-		// Debug: Log the complete object in JSON format
-		objJSON, _ := json.MarshalIndent(obj.Object, "", "  ")
-		fmt.Printf("\n🔍 FULL OBJECT RECEIVED:\n%s\n\n", string(objJSON))
-
-		// Send to pipeline
-		pipeline.SendEvent(ResourceEvent{
-			Type:          EventType(event.Type),
-			ResourceKind:  kind,
-			Namespace:     obj.GetNamespace(),
-			Name:          obj.GetName(),
-			Object:        obj,
-			Timestamp:     time.Now(),
-			ManagedFields: obj.GetManagedFields(),
-		})
+		// The API server closes watches routinely (timeouts, network blips). A watch that stayed
+		// open for a while resets the backoff; one that keeps closing immediately backs off
+		if time.Since(watchStarted) >= minHealthyWatchDuration {
+			backoff = newWatchBackoff()
+		}
+		delay := backoff.Step()
+		reconnectAttrs := append(watchAttrs(kind, scope), slog.String("resource_version", resourceVersion), slog.Duration("retry_in", delay))
+		if reconnect {
+			logWarn(fmt.Sprintf("🔁 Watch error for %s in %s, reconnecting from resourceVersion %s in %s", kind, scope, resourceVersion, delay.Round(time.Millisecond)),
+				"watch error, reconnecting", reconnectAttrs...)
+		} else {
+			logInfo(fmt.Sprintf("🔁 Watch for %s in %s closed, reconnecting from resourceVersion %s in %s", kind, scope, resourceVersion, delay.Round(time.Millisecond)),
+				"watch closed, reconnecting", reconnectAttrs...)
+		}
+		if !sleepWithContext(ctx, delay) {
+			return nil
+		}
 	}
 }
 
-// watchAllNamespaces watches resources across all namespaces
-func watchAllNamespaces(
-	dynamicClient dynamic.Interface,
-	gvr schema.GroupVersionResource,
+// minHealthyWatchDuration is how long a watch must stay open before its reconnect backoff resets
+const minHealthyWatchDuration = 30 * time.Second
+
+// newWatchBackoff returns the exponential backoff used between watch reconnect attempts
+func newWatchBackoff() *wait.Backoff {
+	return &wait.Backoff{
+		Duration: 500 * time.Millisecond,
+		Factor:   2,
+		Jitter:   0.1,
+		Steps:    math.MaxInt32,
+		Cap:      time.Minute,
+	}
+}
+
+// sleepWithContext waits for d; returns false if ctx was cancelled first
+func sleepWithContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// isPermanentWatchError reports whether retrying a failed watch cannot succeed
+// (the resource is no longer served or we aren't allowed to watch it)
+func isPermanentWatchError(err error) bool {
+	return apierrors.IsNotFound(err) || apierrors.IsForbidden(err) || apierrors.IsMethodNotSupported(err)
+}
+
+// listExisting lists existing resources, sends them to the pipeline as ADDED events and
+// returns the List's resourceVersion to start the watch from ("" if the List failed).
+// listLimiter may be nil for re-lists outside the initial List phase
+func listExisting(
+	ctx context.Context,
+	client resourceClient,
 	kind string,
+	scope string,
+	listOptions metav1.ListOptions,
 	pipeline *EventPipeline,
-) {
-	resourceName := gvr.Resource
+	opts WatchOptions,
+	listLimiter *ListLimiter,
+) string {
+	listLimiter.Acquire()
+	logInfo(fmt.Sprintf("📋 Listing existing %s in %s...", kind, scope), "listing existing resources", watchAttrs(kind, scope)...)
 
-	// First, list existing resources across all namespaces
-	fmt.Printf("📋 Listing existing %s across all namespaces...\n", kind)
-	existingResources, err := dynamicClient.Resource(gvr).List(
-		context.TODO(),
-		metav1.ListOptions{},
-	)
+	// Events are sent page by page, so the pipeline starts working before the List completes
+	resourceVersion, count, err := listPages(ctx, client, listOptions, opts.PageSize, opts.Breaker, func(items []unstructured.Unstructured) {
+		for _, resource := range items {
+			logInfo(fmt.Sprintf("   Found existing %s: %s/%s", kind, resource.GetNamespace(), resource.GetName()),
+				"found existing resource", resourceAttrs(kind, resource.GetNamespace(), resource.GetName())...)
 
-	if err == nil && len(existingResources.Items) > 0 {
-		for _, resource := range existingResources.Items {
-			fmt.Printf("   Found existing %s: %s/%s\n",
-				kind, resource.GetNamespace(), resource.GetName())
-
-			resourceCopy := resource.DeepCopy()
+			resourceCopy := redactObject(resource.DeepCopy())
 			pipeline.SendEvent(ResourceEvent{
 				Type:          EventTypeAdded,
 				ResourceKind:  kind,
@@ -140,44 +359,26 @@ func watchAllNamespaces(
 				ManagedFields: resourceCopy.GetManagedFields(),
 			})
 		}
-	} else if err != nil {
-		fmt.Printf("   ⚠️  Could not list %s: %v\n", resourceName, err)
-	}
+	})
+	listLimiter.Release(kind, scope, count)
 
-	// Now start watching for changes across all namespaces
-	watcher, err := dynamicClient.Resource(gvr).Watch(
-		context.TODO(),
-		metav1.ListOptions{},
-	)
 	if err != nil {
-		fmt.Printf("⚠️  Failed to watch %s across all namespaces: %v\n", resourceName, err)
-		return
+		logWarn(fmt.Sprintf("   ⚠️  Could not list %s: %v", kind, err),
+			"could not list resources", append(watchAttrs(kind, scope), slog.String("error", err.Error()))...)
+		return ""
 	}
-	defer watcher.Stop()
-
-	fmt.Printf("✅ Watching %s across all namespaces for changes\n", kind)
 
-	events := watcher.ResultChan()
+	return resourceVersion
+}
 
-	for event := range events {
-		obj, ok := event.Object.(*unstructured.Unstructured)
-		if !ok {
-			continue
-		}
+// watchListOptions returns the resource's List options (selectors) set up to watch from resourceVersion
+func watchListOptions(listOptions metav1.ListOptions, resourceVersion string) metav1.ListOptions {
+	listOptions.ResourceVersion = resourceVersion
+	listOptions.AllowWatchBookmarks = true
+	return listOptions
+}
 
-		// Debug: Log the complete object in JSON format
-		objJSON, _ := json.MarshalIndent(obj.Object, "", "  ")
-		fmt.Printf("\n🔍 FULL OBJECT RECEIVED (all namespaces):\n%s\n\n", string(objJSON))
-
-		// Send to pipeline
-		pipeline.SendEvent(ResourceEvent{
-			Type:          EventType(event.Type),
-			ResourceKind:  kind,
-			Namespace:     obj.GetNamespace(),
-			Name:          obj.GetName(),
-			Object:        obj,
-			Timestamp:     time.Now(),
-			ManagedFields: obj.GetManagedFields(),
-		})
-	}
+// isResourceVersionExpired reports whether err means the requested resourceVersion is too old (410 Gone)
+func isResourceVersionExpired(err error) bool {
+	return apierrors.IsGone(err) || apierrors.IsResourceExpired(err)
 }
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Typed accessors over unstructured Envoy Gateway (gateway.envoyproxy.io/v1alpha1) resources.
+// They keep the CRD field paths in one place and report found=false when a field is absent
+// or has an unexpected type, instead of every caller walking the nested maps itself
+
+// securityPolicyAuthMethods are the SecurityPolicy spec fields that each enable an auth mechanism
+var securityPolicyAuthMethods = []string{"basicAuth", "jwt", "oidc", "apiKeyAuth", "extAuth", "authorization"}
+
+// RateLimitSpec is the rate limit configuration of a BackendTrafficPolicy
+type RateLimitSpec struct {
+	Type  string        `json:"type"`  // Global or Local
+	Rules []interface{} `json:"rules"` // rules of the configured type
+}
+
+// EnvoyProxyProviderType returns spec.provider.type of an EnvoyProxy (e.g. Kubernetes)
+func EnvoyProxyProviderType(obj *unstructured.Unstructured) (string, bool) {
+	providerType, found, err := unstructured.NestedString(obj.Object, "spec", "provider", "type")
+	if err != nil || !found {
+		return "", false
+	}
+	return providerType, true
+}
+
+// EnvoyProxyReplicas returns the Envoy deployment replica count of a Kubernetes-provider EnvoyProxy
+func EnvoyProxyReplicas(obj *unstructured.Unstructured) (int64, bool) {
+	replicas, found, err := unstructured.NestedInt64(obj.Object, "spec", "provider", "kubernetes", "envoyDeployment", "replicas")
+	if err != nil || !found {
+		return 0, false
+	}
+	return replicas, true
+}
+
+// SecurityPolicyTargetRef returns the kind and name of the resource a SecurityPolicy attaches to.
+// It reads the deprecated single spec.targetRef first, then the first entry of spec.targetRefs
+func SecurityPolicyTargetRef(obj *unstructured.Unstructured) (kind, name string, found bool) {
+	if ref, ok, err := unstructured.NestedMap(obj.Object, "spec", "targetRef"); err == nil && ok {
+		return targetRefKindName(ref)
+	}
+
+	refs, ok, err := unstructured.NestedSlice(obj.Object, "spec", "targetRefs")
+	if err != nil || !ok || len(refs) == 0 {
+		return "", "", false
+	}
+	ref, ok := refs[0].(map[string]interface{})
+	if !ok {
+		return "", "", false
+	}
+	return targetRefKindName(ref)
+}
+
+// SecurityPolicyAuthMethods returns the auth mechanisms a SecurityPolicy configures (e.g. jwt, oidc)
+func SecurityPolicyAuthMethods(obj *unstructured.Unstructured) []string {
+	methods := make([]string, 0)
+	for _, method := range securityPolicyAuthMethods {
+		if _, found, err := unstructured.NestedMap(obj.Object, "spec", method); err == nil && found {
+			methods = append(methods, method)
+		}
+	}
+	return methods
+}
+
+// BackendTrafficPolicyRateLimit returns the rate limit type and the rules configured for it
+func BackendTrafficPolicyRateLimit(obj *unstructured.Unstructured) (*RateLimitSpec, bool) {
+	limitType, found, err := unstructured.NestedString(obj.Object, "spec", "rateLimit", "type")
+	if err != nil || !found {
+		return nil, false
+	}
+
+	// The rules live under spec.rateLimit.global or spec.rateLimit.local depending on the type
+	field := "global"
+	if limitType == "Local" {
+		field = "local"
+	}
+	rules, _, _ := unstructured.NestedSlice(obj.Object, "spec", "rateLimit", field, "rules")
+
+	return &RateLimitSpec{Type: limitType, Rules: rules}, true
+}
+
+// targetRefKindName extracts kind and name from a policy target reference
+func targetRefKindName(ref map[string]interface{}) (string, string, bool) {
+	kind, kindOK := ref["kind"].(string)
+	name, nameOK := ref["name"].(string)
+	if !kindOK || !nameOK {
+		return "", "", false
+	}
+	return kind, name, true
+}
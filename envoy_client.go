@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+// Envoy Gateway GroupVersionResources, shared by EnvoyGatewayClient and the default watcher config
+var (
+	EnvoyProxyGVR           = schema.GroupVersionResource{Group: "gateway.envoyproxy.io", Version: "v1alpha1", Resource: "envoyproxies"}
+	BackendTrafficPolicyGVR = schema.GroupVersionResource{Group: "gateway.envoyproxy.io", Version: "v1alpha1", Resource: "backendtrafficpolicies"}
+	SecurityPolicyGVR       = schema.GroupVersionResource{Group: "gateway.envoyproxy.io", Version: "v1alpha1", Resource: "securitypolicies"}
+	ClientTrafficPolicyGVR  = schema.GroupVersionResource{Group: "gateway.envoyproxy.io", Version: "v1alpha1", Resource: "clienttrafficpolicies"}
+)
+
+// envoyGatewayGVRs maps Envoy Gateway kinds to their GroupVersionResource
+var envoyGatewayGVRs = map[string]schema.GroupVersionResource{
+	"EnvoyProxy":           EnvoyProxyGVR,
+	"BackendTrafficPolicy": BackendTrafficPolicyGVR,
+	"SecurityPolicy":       SecurityPolicyGVR,
+	"ClientTrafficPolicy":  ClientTrafficPolicyGVR,
+}
+
+// EnvoyGatewayClient performs write operations on Envoy Gateway resources using the dynamic client
+type EnvoyGatewayClient struct {
+	dynamicClient dynamic.Interface
+}
+
+// NewEnvoyGatewayClient creates a new Envoy Gateway client
+func NewEnvoyGatewayClient(dynamicClient dynamic.Interface) *EnvoyGatewayClient {
+	return &EnvoyGatewayClient{
+		dynamicClient: dynamicClient,
+	}
+}
+
+// resourceFor returns the namespaced dynamic resource client for an Envoy Gateway object
+func (c *EnvoyGatewayClient) resourceFor(obj *unstructured.Unstructured) (dynamic.ResourceInterface, error) {
+	gvr, ok := envoyGatewayGVRs[obj.GetKind()]
+	if !ok {
+		return nil, fmt.Errorf("unsupported Envoy Gateway kind: %q", obj.GetKind())
+	}
+	namespace := obj.GetNamespace()
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+	return c.dynamicClient.Resource(gvr).Namespace(namespace), nil
+}
+
+// Create creates an Envoy Gateway object. With dryRun the API server runs admission and
+// schema validation and returns what it would create, without persisting anything
+func (c *EnvoyGatewayClient) Create(obj *unstructured.Unstructured, dryRun bool) (*unstructured.Unstructured, error) {
+	resource, err := c.resourceFor(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	createOptions := metav1.CreateOptions{}
+	if dryRun {
+		createOptions.DryRun = []string{metav1.DryRunAll}
+	}
+
+	created, err := resource.Create(ctx, obj, createOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s %s/%s: %w", obj.GetKind(), obj.GetNamespace(), obj.GetName(), err)
+	}
+	return created, nil
+}
+
+// DryRunCreate validates an Envoy Gateway object against the server without persisting it
+func (c *EnvoyGatewayClient) DryRunCreate(obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	return c.Create(obj, true)
+}
+
+// CreateEnvoyProxy creates an EnvoyProxy (optionally as a server-side dry run)
+func (c *EnvoyGatewayClient) CreateEnvoyProxy(obj *unstructured.Unstructured, dryRun bool) (*unstructured.Unstructured, error) {
+	if obj.GetKind() != "EnvoyProxy" {
+		return nil, fmt.Errorf("expected kind EnvoyProxy, got %q", obj.GetKind())
+	}
+	return c.Create(obj, dryRun)
+}
+
+// CreateSecurityPolicy creates a SecurityPolicy (optionally as a server-side dry run)
+func (c *EnvoyGatewayClient) CreateSecurityPolicy(obj *unstructured.Unstructured, dryRun bool) (*unstructured.Unstructured, error) {
+	if obj.GetKind() != "SecurityPolicy" {
+		return nil, fmt.Errorf("expected kind SecurityPolicy, got %q", obj.GetKind())
+	}
+	return c.Create(obj, dryRun)
+}
+
+// Apply server-side applies an Envoy Gateway object in namespace ("" = the object's namespace,
+// else default) as fieldManager. Force takes over fields owned by other managers instead of
+// failing with a conflict, making repeated applies idempotent
+func (c *EnvoyGatewayClient) Apply(ctx context.Context, namespace string, obj *unstructured.Unstructured, fieldManager string) (*unstructured.Unstructured, error) {
+	gvr, ok := envoyGatewayGVRs[obj.GetKind()]
+	if !ok {
+		return nil, fmt.Errorf("unsupported Envoy Gateway kind: %q", obj.GetKind())
+	}
+	if fieldManager == "" {
+		return nil, fmt.Errorf("a field manager is required to apply %s %s", obj.GetKind(), obj.GetName())
+	}
+	if namespace == "" {
+		namespace = obj.GetNamespace()
+	}
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+	if obj.GetNamespace() != "" && obj.GetNamespace() != namespace {
+		return nil, fmt.Errorf("%s %s is in namespace %q, not %q", obj.GetKind(), obj.GetName(), obj.GetNamespace(), namespace)
+	}
+	obj = obj.DeepCopy()
+	obj.SetNamespace(namespace)
+
+	applied, err := c.dynamicClient.Resource(gvr).Namespace(namespace).Apply(ctx, obj.GetName(), obj,
+		metav1.ApplyOptions{FieldManager: fieldManager, Force: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply %s %s/%s: %w", obj.GetKind(), namespace, obj.GetName(), err)
+	}
+	return applied, nil
+}
+
+// applyKind is Apply restricted to one kind
+func (c *EnvoyGatewayClient) applyKind(ctx context.Context, kind, namespace string, obj *unstructured.Unstructured, fieldManager string) (*unstructured.Unstructured, error) {
+	if obj.GetKind() != kind {
+		return nil, fmt.Errorf("expected kind %s, got %q", kind, obj.GetKind())
+	}
+	return c.Apply(ctx, namespace, obj, fieldManager)
+}
+
+// ApplyEnvoyProxy server-side applies an EnvoyProxy
+func (c *EnvoyGatewayClient) ApplyEnvoyProxy(ctx context.Context, namespace string, obj *unstructured.Unstructured, fieldManager string) (*unstructured.Unstructured, error) {
+	return c.applyKind(ctx, "EnvoyProxy", namespace, obj, fieldManager)
+}
+
+// ApplySecurityPolicy server-side applies a SecurityPolicy
+func (c *EnvoyGatewayClient) ApplySecurityPolicy(ctx context.Context, namespace string, obj *unstructured.Unstructured, fieldManager string) (*unstructured.Unstructured, error) {
+	return c.applyKind(ctx, "SecurityPolicy", namespace, obj, fieldManager)
+}
+
+// ApplyBackendTrafficPolicy server-side applies a BackendTrafficPolicy
+func (c *EnvoyGatewayClient) ApplyBackendTrafficPolicy(ctx context.Context, namespace string, obj *unstructured.Unstructured, fieldManager string) (*unstructured.Unstructured, error) {
+	return c.applyKind(ctx, "BackendTrafficPolicy", namespace, obj, fieldManager)
+}
+
+// ApplyClientTrafficPolicy server-side applies a ClientTrafficPolicy
+func (c *EnvoyGatewayClient) ApplyClientTrafficPolicy(ctx context.Context, namespace string, obj *unstructured.Unstructured, fieldManager string) (*unstructured.Unstructured, error) {
+	return c.applyKind(ctx, "ClientTrafficPolicy", namespace, obj, fieldManager)
+}
+
+// Patch patches an Envoy Gateway object in place with a JSON merge patch (types.MergePatchType) or
+// a JSON patch (types.JSONPatchType), so a single field can change without re-sending the whole
+// object. Strategic merge patches aren't supported by custom resources; use Apply for server-side apply
+func (c *EnvoyGatewayClient) Patch(ctx context.Context, kind, namespace, name string, patchType types.PatchType, data []byte) (*unstructured.Unstructured, error) {
+	gvr, ok := envoyGatewayGVRs[kind]
+	if !ok {
+		return nil, fmt.Errorf("unsupported Envoy Gateway kind: %q", kind)
+	}
+	switch patchType {
+	case types.MergePatchType, types.JSONPatchType:
+	default:
+		return nil, fmt.Errorf("unsupported patch type for %s: %q (use %q or %q)", kind, patchType, types.MergePatchType, types.JSONPatchType)
+	}
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+
+	patched, err := c.dynamicClient.Resource(gvr).Namespace(namespace).Patch(ctx, name, patchType, data, metav1.PatchOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to patch %s %s/%s: %w", kind, namespace, name, err)
+	}
+	return patched, nil
+}
+
+// PatchEnvoyProxy patches an EnvoyProxy, see Patch
+func (c *EnvoyGatewayClient) PatchEnvoyProxy(ctx context.Context, namespace, name string, patchType types.PatchType, data []byte) (*unstructured.Unstructured, error) {
+	return c.Patch(ctx, "EnvoyProxy", namespace, name, patchType, data)
+}
+
+// PatchSecurityPolicy patches a SecurityPolicy, see Patch
+func (c *EnvoyGatewayClient) PatchSecurityPolicy(ctx context.Context, namespace, name string, patchType types.PatchType, data []byte) (*unstructured.Unstructured, error) {
+	return c.Patch(ctx, "SecurityPolicy", namespace, name, patchType, data)
+}
+
+// PatchBackendTrafficPolicy patches a BackendTrafficPolicy, see Patch
+func (c *EnvoyGatewayClient) PatchBackendTrafficPolicy(ctx context.Context, namespace, name string, patchType types.PatchType, data []byte) (*unstructured.Unstructured, error) {
+	return c.Patch(ctx, "BackendTrafficPolicy", namespace, name, patchType, data)
+}
+
+// PatchClientTrafficPolicy patches a ClientTrafficPolicy, see Patch
+func (c *EnvoyGatewayClient) PatchClientTrafficPolicy(ctx context.Context, namespace, name string, patchType types.PatchType, data []byte) (*unstructured.Unstructured, error) {
+	return c.Patch(ctx, "ClientTrafficPolicy", namespace, name, patchType, data)
+}
+
+// Watch feeds the Envoy Gateway objects of a kind in namespaces (empty = all) into pipeline as
+// ResourceEvents, the same way configured resources are watched, so debouncing, managedFields
+// status filtering, diffing and storage all apply. Blocks like WatchResource
+func (c *EnvoyGatewayClient) Watch(ctx context.Context, kind string, namespaces []string, pipeline *EventPipeline, opts WatchOptions) error {
+	gvr, ok := envoyGatewayGVRs[kind]
+	if !ok {
+		return fmt.Errorf("unsupported Envoy Gateway kind: %q", kind)
+	}
+	return WatchResource(ctx, c.dynamicClient, gvr, namespaces, kind, metav1.ListOptions{}, pipeline, opts)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// compareEnvoyProxies reports changes to an EnvoyProxy's provider type and replica count.
+// Switching providers redeploys the data plane and is raised as an alert
+func compareEnvoyProxies(old, new *unstructured.Unstructured) *ComparatorResult {
+	result := newComparatorResult()
+
+	oldType, _ := EnvoyProxyProviderType(old)
+	newType, _ := EnvoyProxyProviderType(new)
+	if oldType != newType {
+		result.addChange("spec.provider.type", oldType, newType)
+		result.Alerts = append(result.Alerts,
+			fmt.Sprintf("EnvoyProxy %s/%s: provider changed %q → %q", new.GetNamespace(), new.GetName(), oldType, newType))
+	}
+
+	oldReplicas, oldSet := EnvoyProxyReplicas(old)
+	newReplicas, newSet := EnvoyProxyReplicas(new)
+	if oldSet != newSet || oldReplicas != newReplicas {
+		result.addChange("spec.provider.kubernetes.envoyDeployment.replicas", oldReplicas, newReplicas)
+	}
+
+	return result
+}
+
+// compareSecurityPolicies reports changes to a SecurityPolicy's target and auth mechanisms.
+// Retargeting a policy or removing an auth mechanism is raised as an alert
+func compareSecurityPolicies(old, new *unstructured.Unstructured) *ComparatorResult {
+	result := newComparatorResult()
+
+	oldKind, oldName, _ := SecurityPolicyTargetRef(old)
+	newKind, newName, _ := SecurityPolicyTargetRef(new)
+	if oldKind != newKind || oldName != newName {
+		oldTarget, newTarget := oldKind+"/"+oldName, newKind+"/"+newName
+		result.addChange("spec.targetRef", oldTarget, newTarget)
+		result.Alerts = append(result.Alerts,
+			fmt.Sprintf("SecurityPolicy %s/%s: retargeted %s → %s", new.GetNamespace(), new.GetName(), oldTarget, newTarget))
+	}
+
+	oldMethods := SecurityPolicyAuthMethods(old)
+	newMethods := SecurityPolicyAuthMethods(new)
+	if !reflect.DeepEqual(oldMethods, newMethods) {
+		result.addChange("authMethods", oldMethods, newMethods)
+	}
+	for _, method := range oldMethods {
+		if !slices.Contains(newMethods, method) {
+			result.Alerts = append(result.Alerts,
+				fmt.Sprintf("SecurityPolicy %s/%s: %s removed", new.GetNamespace(), new.GetName(), method))
+		}
+	}
+
+	return result
+}
+
+// compareBackendTrafficPolicies reports rate limit changes. Removing the rate limit is raised as an alert
+func compareBackendTrafficPolicies(old, new *unstructured.Unstructured) *ComparatorResult {
+	result := newComparatorResult()
+
+	oldLimit, oldSet := BackendTrafficPolicyRateLimit(old)
+	newLimit, newSet := BackendTrafficPolicyRateLimit(new)
+	if !reflect.DeepEqual(oldLimit, newLimit) {
+		result.addChange("spec.rateLimit", oldLimit, newLimit)
+	}
+	if oldSet && !newSet {
+		result.Alerts = append(result.Alerts,
+			fmt.Sprintf("BackendTrafficPolicy %s/%s: %s rate limit removed", new.GetNamespace(), new.GetName(), oldLimit.Type))
+	}
+
+	return result
+}
@@ -1,14 +1,24 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"reflect"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/watch"
 )
 
 // EventType defines the type of event
@@ -18,27 +28,67 @@ const (
 	EventTypeAdded    EventType = "ADDED"
 	EventTypeModified EventType = "MODIFIED"
 	EventTypeDeleted  EventType = "DELETED"
+	EventTypeBookmark EventType = "BOOKMARK" // watch progress marker, never stored
+	EventTypeError    EventType = "ERROR"    // watch error, never stored
+	EventTypeUnknown  EventType = "UNKNOWN"
 )
 
+// EventTypeFromWatch maps a watch.EventType onto the complete EventType enum
+func EventTypeFromWatch(watchType watch.EventType) EventType {
+	switch watchType {
+	case watch.Added:
+		return EventTypeAdded
+	case watch.Modified:
+		return EventTypeModified
+	case watch.Deleted:
+		return EventTypeDeleted
+	case watch.Bookmark:
+		return EventTypeBookmark
+	case watch.Error:
+		return EventTypeError
+	}
+	return EventTypeUnknown
+}
+
 // ResourceEvent represents a standardized event from any watcher
 type ResourceEvent struct {
 	Type          EventType
-	ResourceKind  string // Changed from ResourceType to string
+	RawType       watch.EventType // original watch event type ("" for synthetic events such as List results)
+	ResourceKind  string          // Changed from ResourceType to string
 	Namespace     string
 	Name          string
 	Object        interface{}
 	Timestamp     time.Time
 	ManagedFields []metav1.ManagedFieldsEntry
+	RootOwner     string // resource key of the followOwned workload owning the object, see OwnerTracker
+
+	CoalescedCount int       // raw watch events this event stands for when debounced (0 = not debounced)
+	FirstSeen      time.Time // when the first of the coalesced events was received
+
+	Refresh bool // re-sent by a resync: diffed against the latest stored version, never dropped as a replay
 }
 
 // ChangeDetails represents the details of what changed
 type ChangeDetails struct {
 	MetadataChanges map[string]interface{} // labels, annotations, etc.
 	SpecChanges     map[string]interface{} // spec field changes
+	KindChanges     map[string]interface{} // kind-specific changes from a registered comparator
+	StatusChanges   map[string]interface{} // status changes, only for kinds set with SetStatusKinds
+	Alerts          []string               // changes flagged by a comparator as needing attention
+	ImageChanges    []ImageChange          // container image updates of a workload
+	RouteChanges    []string               // route rule changes, e.g. "added match Greeter/SayHello → backend api:50051"
+	Conflict        *FieldManagerConflict  // set when this change looks like field-manager contention
+	DiffSkipped     string                 // why a diff was abandoned for exceeding the DiffLimits ("" = not skipped)
+	FieldChanges    []FieldChange          // per-field diff against the latest stored version, set when a change is stored
 	OldObject       interface{}
 	NewObject       interface{}
 }
 
+// HasChanges reports whether any metadata, spec or kind-specific change was detected
+func (cd *ChangeDetails) HasChanges() bool {
+	return len(cd.MetadataChanges) > 0 || len(cd.SpecChanges) > 0 || len(cd.KindChanges) > 0 || len(cd.StatusChanges) > 0
+}
+
 // EventPipeline manages the event processing pipeline
 type EventPipeline struct {
 	eventChannel   chan ResourceEvent
@@ -46,8 +96,39 @@ type EventPipeline struct {
 	stateMutex     sync.RWMutex
 	changeHandlers []ChangeHandler
 	redisManager   *RedisManager
+	tracer         trace.Tracer
+	comparators    map[string]KindComparator
+	changeFilters  map[string]ChangeFilter
+	canonicalizers map[string]Canonicalizer // strip server-side defaults before diffing
+	statusKinds    map[string]bool          // kinds whose status-only updates are recorded
+	owners         *OwnerTracker            // follows objects owned by followOwned workloads (nil = disabled)
+	eventTypes     map[EventType]bool       // event types to record (nil = all)
+	noMFMode       NoManagedFieldsMode
+	sampler        *AdaptiveSampler   // throttles pathologically noisy resources (nil = disabled)
+	conflicts      *ConflictDetector  // flags field-manager contention
+	equality       ObjectEqualityFunc // drops modifications equal to the previous state (nil = never drop)
+	progress       map[string]string  // last bookmarked resourceVersion per kind
+	progressMutex  sync.RWMutex
+	pending        atomic.Int64             // events sent but not yet fully processed
+	changeOutput   *NDJSONWriter            // also receives every stored change (nil = disabled)
+	debounceWindow time.Duration            // coalesce events of a resource within this window (0 = disabled)
+	debounced      map[string]ResourceEvent // latest event per resource held for its window, guarded by stateMutex
+	lastVersions   map[string]string        // last processed resourceVersion per resource (nil = no deduplication)
 }
 
+// NoManagedFieldsMode controls how events whose object carries no managedFields are filtered
+type NoManagedFieldsMode string
+
+const (
+	// NoManagedFieldsCompare falls back to a deep-equal check of labels, annotations and
+	// non-status fields against the previous state
+	NoManagedFieldsCompare NoManagedFieldsMode = "compare"
+	// NoManagedFieldsProcess treats every such event as a relevant change
+	NoManagedFieldsProcess NoManagedFieldsMode = "process"
+	// NoManagedFieldsSkip treats every such event as status-only and drops it
+	NoManagedFieldsSkip NoManagedFieldsMode = "skip"
+)
+
 // ChangeHandler is a function that handles change events
 type ChangeHandler func(event ResourceEvent, changes *ChangeDetails)
 
@@ -58,6 +139,16 @@ func NewEventPipeline(bufferSize int, redisManager *RedisManager) *EventPipeline
 		previousStates: make(map[string]interface{}),
 		changeHandlers: make([]ChangeHandler, 0),
 		redisManager:   redisManager,
+		tracer:         noopTracer,
+		comparators:    defaultComparators(),
+		changeFilters:  defaultChangeFilters(),
+		canonicalizers: defaultCanonicalizers(),
+		conflicts:      NewConflictDetector(),
+		equality:       equalIgnoringManagedFieldsTime,
+		noMFMode:       NoManagedFieldsCompare,
+		progress:       make(map[string]string),
+		debounced:      make(map[string]ResourceEvent),
+		lastVersions:   make(map[string]string),
 	}
 }
 
@@ -66,63 +157,410 @@ func (ep *EventPipeline) RegisterHandler(handler ChangeHandler) {
 	ep.changeHandlers = append(ep.changeHandlers, handler)
 }
 
+// RegisterComparator registers a kind-specific comparator, replacing any existing one for that kind
+func (ep *EventPipeline) RegisterComparator(kind string, comparator KindComparator) {
+	ep.comparators[kind] = comparator
+}
+
+// SetEventTypeFilter restricts processing to the given event types (empty = all)
+func (ep *EventPipeline) SetEventTypeFilter(types []EventType) {
+	if len(types) == 0 {
+		ep.eventTypes = nil
+		return
+	}
+	ep.eventTypes = make(map[EventType]bool, len(types))
+	for _, t := range types {
+		ep.eventTypes[t] = true
+	}
+}
+
+// ParseEventTypes parses a comma-separated list of event types (e.g. "ADDED,DELETED")
+func ParseEventTypes(value string) ([]EventType, error) {
+	types := make([]EventType, 0)
+	for _, part := range strings.Split(value, ",") {
+		part = strings.ToUpper(strings.TrimSpace(part))
+		if part == "" {
+			continue
+		}
+		switch EventType(part) {
+		case EventTypeAdded, EventTypeModified, EventTypeDeleted:
+			types = append(types, EventType(part))
+		default:
+			return nil, fmt.Errorf("unknown event type %q (expected ADDED, MODIFIED or DELETED)", part)
+		}
+	}
+	return types, nil
+}
+
+// SetNoManagedFieldsMode sets how events without managedFields are filtered
+func (ep *EventPipeline) SetNoManagedFieldsMode(mode NoManagedFieldsMode) error {
+	switch mode {
+	case NoManagedFieldsCompare, NoManagedFieldsProcess, NoManagedFieldsSkip:
+		ep.noMFMode = mode
+		return nil
+	}
+	return fmt.Errorf("unknown no-managed-fields mode %q (expected compare, process or skip)", mode)
+}
+
+// SetAdaptiveSampler enables automatic throttling of high-frequency resources (nil disables it)
+func (ep *EventPipeline) SetAdaptiveSampler(sampler *AdaptiveSampler) {
+	ep.sampler = sampler
+}
+
+// ThrottledResources returns the resources currently throttled by the adaptive sampler
+func (ep *EventPipeline) ThrottledResources() []ThrottledResource {
+	return ep.sampler.Throttled()
+}
+
+// QueueLength returns the number of events waiting to be processed
+func (ep *EventPipeline) QueueLength() int {
+	return len(ep.eventChannel)
+}
+
+// SetChangeFilter sets the change filter for a kind; a nil filter removes it
+func (ep *EventPipeline) SetChangeFilter(kind string, filter ChangeFilter) {
+	if filter == nil {
+		delete(ep.changeFilters, kind)
+		return
+	}
+	ep.changeFilters[kind] = filter
+}
+
+// SetCanonicalKinds limits canonicalization before diffing to the given kinds (empty disables it)
+func (ep *EventPipeline) SetCanonicalKinds(kinds []string) {
+	available := defaultCanonicalizers()
+	ep.canonicalizers = make(map[string]Canonicalizer, len(kinds))
+	for _, kind := range kinds {
+		if canonicalizer, ok := available[kind]; ok {
+			ep.canonicalizers[kind] = canonicalizer
+		}
+	}
+}
+
+// SetEqualityFunc sets how modifications are compared to the previous state; equal ones are
+// dropped so a pure re-apply produces no stored version (nil disables the check)
+func (ep *EventPipeline) SetEqualityFunc(equality ObjectEqualityFunc) {
+	ep.equality = equality
+}
+
+// SetTracer enables span recording for processed events (nil disables tracing)
+func (ep *EventPipeline) SetTracer(tracer trace.Tracer) {
+	if tracer == nil {
+		tracer = noopTracer
+	}
+	ep.tracer = tracer
+}
+
 // SendEvent sends an event to the pipeline
 func (ep *EventPipeline) SendEvent(event ResourceEvent) {
+	if ep.debounceWindow > 0 && ep.debounce(event) {
+		return
+	}
+	ep.pending.Add(1)
 	ep.eventChannel <- event
 }
 
 // Start starts the event processing pipeline
 func (ep *EventPipeline) Start() {
-	fmt.Println("🚀 Event Pipeline Started - Processing events...\n")
+	logInfo("🚀 Event Pipeline Started - Processing events...\n", "event pipeline started")
 
 	for event := range ep.eventChannel {
+		start := time.Now()
 		ep.processEvent(event)
+		observePipelineLatency(time.Since(start))
+		ep.pending.Add(-1)
+	}
+}
+
+// Drain waits until every sent event has been processed, or until timeout elapses.
+// Returns the number of events still unprocessed (0 if fully drained)
+func (ep *EventPipeline) Drain(timeout time.Duration) int64 {
+	deadline := time.Now().Add(timeout)
+	for {
+		remaining := ep.pending.Load()
+		if remaining == 0 || time.Now().After(deadline) {
+			return remaining
+		}
+		time.Sleep(50 * time.Millisecond)
 	}
 }
 
 // processEvent processes a single event
 func (ep *EventPipeline) processEvent(event ResourceEvent) {
+	recordPipelineEvent(event.ResourceKind, event.Type)
+
+	// Bookmarks and errors describe the watch itself, not a resource change
+	switch event.Type {
+	case EventTypeBookmark:
+		ep.recordProgress(event)
+		return
+	case EventTypeError:
+		if statusErr, ok := event.Object.(apierrors.APIStatus); ok {
+			status := statusErr.Status()
+			logWarn(fmt.Sprintf("⚠️  Watch error for %s: %s (reason %s, code %d) - watcher will reconnect",
+				event.ResourceKind, status.Message, status.Reason, status.Code),
+				"watch error", slog.String("kind", event.ResourceKind), slog.String("error", status.Message),
+				slog.String("reason", string(status.Reason)), slog.Int("code", int(status.Code)))
+		} else {
+			logWarn(fmt.Sprintf("⚠️  Watch error for %s: %v (watcher will reconnect)", event.ResourceKind, event.Object),
+				"watch error", slog.String("kind", event.ResourceKind), slog.String("error", fmt.Sprint(event.Object)))
+		}
+		return
+	case EventTypeUnknown:
+		logWarn(fmt.Sprintf("⚠️  Ignoring unknown watch event type %q for %s", event.RawType, event.ResourceKind),
+			"ignoring unknown watch event type", slog.String("kind", event.ResourceKind), slog.String("event_type", string(event.RawType)))
+		return
+	}
+
+	// Tag objects owned by a followOwned workload with it, and drop followed objects that don't
+	// belong to one. Events that were waiting for this object's ownership are processed after it
+	keep, released := ep.owners.Resolve(&event)
+	defer func() {
+		for _, waiting := range released {
+			ep.processEvent(waiting)
+		}
+	}()
+	if !keep {
+		return
+	}
+
 	// Generate unique key for this resource
 	key := fmt.Sprintf("%s/%s/%s", event.ResourceKind, event.Name, event.Namespace)
 
-	// Check if this is a metadata/spec change
+	// Drop events a watch replays after reconnecting: their resourceVersion was already processed
+	if ep.isReplayed(key, event) && !event.Refresh {
+		return
+	}
+
+	// Drop event types that weren't selected with --event-types
+	if ep.eventTypes != nil && !ep.eventTypes[event.Type] {
+		return
+	}
+
+	// Check if this is a metadata/spec change (or a status change of a kind watching status)
 	if !ep.hasRelevantChanges(event) && event.Type != EventTypeAdded {
 		return // Skip status-only changes
 	}
 
+	ctx, span := ep.tracer.Start(context.Background(), "pipeline.processEvent", trace.WithAttributes(
+		attribute.String("k8s.kind", event.ResourceKind),
+		attribute.String("k8s.namespace", event.Namespace),
+		attribute.String("k8s.name", event.Name),
+		attribute.String("event.type", string(event.Type)),
+	))
+	defer span.End()
+
 	// Get previous state
 	ep.stateMutex.RLock()
 	oldState := ep.previousStates[key]
 	ep.stateMutex.RUnlock()
 
+	// A resync compares with what Redis holds, so history lost or left stale there is stored again
+	if event.Refresh && ep.redisManager != nil {
+		oldState = nil
+		if stored := ep.storedState(key); stored != nil {
+			oldState = stored
+		}
+	}
+
+	// Drop pure re-applies: nothing changed beyond bookkeeping such as managedFields timestamps
+	if event.Type == EventTypeModified && ep.equality != nil {
+		old, oldOk := oldState.(*unstructured.Unstructured)
+		new, newOk := event.Object.(*unstructured.Unstructured)
+		if oldOk && newOk && ep.equality(old, new) {
+			return
+		}
+	}
+
+	// Kind-specific filters drop known churn (e.g. Lease renewals)
+	if filter, ok := ep.changeFilters[event.ResourceKind]; ok && event.Type == EventTypeModified {
+		old, oldOk := oldState.(*unstructured.Unstructured)
+		new, newOk := event.Object.(*unstructured.Unstructured)
+		if oldOk && newOk && !filter(old, new) {
+			return
+		}
+	}
+
+	// Throttle resources that change pathologically often (only modifications are sampled)
+	if event.Type == EventTypeModified && !ep.sampler.Allow(key) {
+		return
+	}
+
 	// Calculate changes
+	// ADDED events are diffed too when a previous state exists (seeded from Redis after a restart)
 	var changes *ChangeDetails
-	if event.Type == EventTypeModified && oldState != nil {
-		changes = ep.calculateChanges(oldState, event.Object)
+	if (event.Type == EventTypeModified || event.Type == EventTypeAdded) && oldState != nil {
+		_, diffSpan := ep.tracer.Start(ctx, "pipeline.diff")
+		changes = ep.calculateChanges(event.ResourceKind, oldState, event.Object)
+		diffSpan.End()
 	} else {
 		changes = &ChangeDetails{
 			MetadataChanges: make(map[string]interface{}),
 			SpecChanges:     make(map[string]interface{}),
+			KindChanges:     make(map[string]interface{}),
+			StatusChanges:   make(map[string]interface{}),
 			NewObject:       event.Object,
 		}
 	}
 
+	// Flag successive changes by different field managers to the same fields (apply contention)
+	if event.Type == EventTypeModified {
+		old, oldOk := oldState.(*unstructured.Unstructured)
+		new, newOk := event.Object.(*unstructured.Unstructured)
+		if oldOk && newOk {
+			if conflict := ep.conflicts.Observe(key, event.ManagedFields, old, new); conflict != nil {
+				changes.Conflict = conflict
+				logWarn(fmt.Sprintf("⚔️  FIELD MANAGER CONFLICT: %s - %s (likely server-side apply contention)", key, conflict),
+					"field manager conflict", append(resourceAttrs(event.ResourceKind, event.Namespace, event.Name), slog.String("conflict", conflict.String()))...)
+			}
+		}
+	}
+
 	// Store full object changes to Redis with versioning
+	_, storeSpan := ep.tracer.Start(ctx, "pipeline.redisWrite")
 	ep.storeVersionedResourceChange(event, oldState, changes)
+	storeSpan.End()
 
 	// Call all registered handlers
-	for _, handler := range ep.changeHandlers {
+	for i, handler := range ep.changeHandlers {
+		_, handlerSpan := ep.tracer.Start(ctx, fmt.Sprintf("pipeline.handler[%d]", i))
 		handler(event, changes)
+		handlerSpan.End()
 	}
 
-	// Update state
+	// Update state (deleted resources are forgotten so a resync doesn't re-report them)
 	ep.stateMutex.Lock()
-	ep.previousStates[key] = ep.deepCopyObject(event.Object)
+	if event.Type == EventTypeDeleted {
+		delete(ep.previousStates, key)
+		ep.conflicts.Forget(key)
+	} else {
+		ep.previousStates[key] = ep.deepCopyObject(event.Object)
+	}
 	ep.stateMutex.Unlock()
 }
 
+// relevantFieldKeys are the top-level managedFields entries that count as a configuration change.
+// Kinds without a spec (e.g. webhook configurations) keep their configuration in other top-level fields
+var relevantFieldKeys = map[string]bool{
+	"f:metadata":                     true,
+	"f:spec":                         true,
+	"f:webhooks":                     true,
+	"f:secrets":                      true, // ServiceAccount
+	"f:imagePullSecrets":             true, // ServiceAccount
+	"f:automountServiceAccountToken": true, // ServiceAccount
+	"f:data":                         true, // ConfigMap, Secret
+	"f:binaryData":                   true, // ConfigMap
+	"f:stringData":                   true, // Secret
+}
+
+// recordProgress notes the resourceVersion carried by a bookmark event
+func (ep *EventPipeline) recordProgress(event ResourceEvent) {
+	obj, ok := event.Object.(*unstructured.Unstructured)
+	if !ok {
+		return
+	}
+	ep.progressMutex.Lock()
+	ep.progress[event.ResourceKind] = obj.GetResourceVersion()
+	ep.progressMutex.Unlock()
+}
+
+// Progress returns the last bookmarked resourceVersion for a kind ("" if none yet)
+func (ep *EventPipeline) Progress(kind string) string {
+	ep.progressMutex.RLock()
+	defer ep.progressMutex.RUnlock()
+	return ep.progress[kind]
+}
+
+// KnownObjects returns copies of the last known state of every resource of the given kind.
+// An empty namespace matches all namespaces
+func (ep *EventPipeline) KnownObjects(kind string, namespace string) []*unstructured.Unstructured {
+	ep.stateMutex.RLock()
+	defer ep.stateMutex.RUnlock()
+
+	known := make([]*unstructured.Unstructured, 0)
+	for _, state := range ep.previousStates {
+		obj, ok := state.(*unstructured.Unstructured)
+		if !ok || obj.GetKind() != kind {
+			continue
+		}
+		if namespace != "" && obj.GetNamespace() != namespace {
+			continue
+		}
+		known = append(known, obj.DeepCopy())
+	}
+	return known
+}
+
+// RestoreStatesFromRedis seeds previousStates with the latest stored snapshot of every resource,
+// so the first event after a restart is diffed against the last known state instead of looking new
+func (ep *EventPipeline) RestoreStatesFromRedis() (int, error) {
+	if ep.redisManager == nil {
+		return 0, nil
+	}
+
+	keys, err := ep.redisManager.GetAllResourceKeys()
+	if err != nil {
+		return 0, err
+	}
+
+	restored := 0
+	for _, key := range keys {
+		restoredObj := ep.storedState(key)
+		if restoredObj == nil {
+			continue
+		}
+
+		ep.stateMutex.Lock()
+		ep.previousStates[key] = restoredObj
+		ep.stateMutex.Unlock()
+		restored++
+	}
+
+	return restored, nil
+}
+
+// storedState returns the latest version stored for a resource as a live-like object, or nil
+// when nothing (readable) is stored
+func (ep *EventPipeline) storedState(key string) *unstructured.Unstructured {
+	stored, err := ep.redisManager.GetLatestObject(key)
+	if err != nil || stored == nil {
+		return nil
+	}
+
+	// Unwrap the StoredObject to get the actual Kubernetes object
+	actualObj := stored
+	if objMap, ok := stored.(map[string]interface{}); ok {
+		if innerObj, hasObject := objMap["object"]; hasObject {
+			actualObj = innerObj
+		}
+	}
+
+	// Round-trip through the unstructured decoder so numbers are int64 like live objects
+	// (plain json.Unmarshal yields float64, which would show up as spurious spec changes)
+	objJSON, err := json.Marshal(actualObj)
+	if err != nil {
+		return nil
+	}
+	restoredObj := &unstructured.Unstructured{}
+	if err := restoredObj.UnmarshalJSON(objJSON); err != nil {
+		return nil
+	}
+	return restoredObj
+}
+
 // hasRelevantChanges checks if event has metadata or spec changes
 func (ep *EventPipeline) hasRelevantChanges(event ResourceEvent) bool {
+	if len(event.ManagedFields) == 0 {
+		switch ep.noMFMode {
+		case NoManagedFieldsProcess:
+			return true
+		case NoManagedFieldsSkip:
+			return false
+		default:
+			return ep.differsFromPreviousState(event)
+		}
+	}
+
 	for _, mf := range event.ManagedFields {
 		if mf.FieldsV1 == nil {
 			continue
@@ -134,7 +572,7 @@ func (ep *EventPipeline) hasRelevantChanges(event ResourceEvent) bool {
 		}
 
 		for key := range fields {
-			if key == "f:metadata" || key == "f:spec" {
+			if relevantFieldKeys[key] || (key == "f:status" && ep.statusKinds[event.ResourceKind]) {
 				return true
 			}
 		}
@@ -142,18 +580,71 @@ func (ep *EventPipeline) hasRelevantChanges(event ResourceEvent) bool {
 	return false
 }
 
-// calculateChanges calculates what changed between old and new objects
-func (ep *EventPipeline) calculateChanges(oldObj, newObj interface{}) *ChangeDetails {
+// differsFromPreviousState is the fallback relevance check for objects without managedFields:
+// it compares labels, annotations and every top-level field except metadata and status
+func (ep *EventPipeline) differsFromPreviousState(event ResourceEvent) bool {
+	key := fmt.Sprintf("%s/%s/%s", event.ResourceKind, event.Name, event.Namespace)
+
+	ep.stateMutex.RLock()
+	oldState := ep.previousStates[key]
+	ep.stateMutex.RUnlock()
+
+	old, oldOk := oldState.(*unstructured.Unstructured)
+	new, newOk := event.Object.(*unstructured.Unstructured)
+	if !oldOk || !newOk {
+		return true
+	}
+
+	if !reflect.DeepEqual(old.GetLabels(), new.GetLabels()) ||
+		!reflect.DeepEqual(old.GetAnnotations(), new.GetAnnotations()) {
+		return true
+	}
+
+	for _, field := range unionKeys(old.Object, new.Object) {
+		if field == "metadata" || (field == "status" && !ep.statusKinds[event.ResourceKind]) {
+			continue
+		}
+		if !reflect.DeepEqual(old.Object[field], new.Object[field]) {
+			return true
+		}
+	}
+	return false
+}
+
+// calculateChanges calculates what changed between old and new objects of a kind
+func (ep *EventPipeline) calculateChanges(kind string, oldObj, newObj interface{}) *ChangeDetails {
 	changes := &ChangeDetails{
 		MetadataChanges: make(map[string]interface{}),
 		SpecChanges:     make(map[string]interface{}),
+		KindChanges:     make(map[string]interface{}),
+		StatusChanges:   make(map[string]interface{}),
 		OldObject:       oldObj,
 		NewObject:       newObj,
 	}
 
-	// Everything is unstructured
-	old := oldObj.(*unstructured.Unstructured)
-	new := newObj.(*unstructured.Unstructured)
+	// Everything is unstructured; anything else can't be compared field by field
+	old, oldOk := oldObj.(*unstructured.Unstructured)
+	new, newOk := newObj.(*unstructured.Unstructured)
+	if !oldOk || !newOk {
+		unexpected := newObj
+		if newOk {
+			unexpected = oldObj
+		}
+		reportCastFailure(&UnexpectedObjectError{
+			Kind:     kind,
+			Context:  "diff",
+			Expected: "*unstructured.Unstructured",
+			Object:   unexpected,
+		})
+		return changes
+	}
+
+	// Diff canonical copies so server-side defaulting doesn't show up as a change
+	if canonicalize, ok := ep.canonicalizers[new.GetKind()]; ok {
+		old, new = old.DeepCopy(), new.DeepCopy()
+		canonicalize(old)
+		canonicalize(new)
+	}
 
 	// Compare labels
 	if !reflect.DeepEqual(old.GetLabels(), new.GetLabels()) {
@@ -176,28 +667,69 @@ func (ep *EventPipeline) calculateChanges(oldObj, newObj interface{}) *ChangeDet
 	newSpec, _, _ := unstructured.NestedMap(new.Object, "spec")
 
 	if !reflect.DeepEqual(oldSpec, newSpec) {
-		changes.SpecChanges["spec"] = map[string]interface{}{
-			"old": oldSpec,
-			"new": newSpec,
+		addSpecFieldChanges(changes, kind, oldSpec, newSpec)
+	}
+
+	if ep.statusKinds[kind] {
+		diffStatus(old, new, changes)
+	}
+
+	if changes.DiffSkipped != "" {
+		logWarn(fmt.Sprintf("⚠️  Diff of %s %s/%s skipped: %s", kind, new.GetNamespace(), new.GetName(), changes.DiffSkipped),
+			"diff skipped", append(resourceAttrs(kind, new.GetNamespace(), new.GetName()), slog.String("reason", changes.DiffSkipped))...)
+	}
+
+	// Kind-specific comparison
+	if comparator, ok := ep.comparators[new.GetKind()]; ok {
+		if result := comparator(old, new); result != nil {
+			for name, change := range result.Changes {
+				changes.KindChanges[name] = change
+			}
+			changes.Alerts = append(changes.Alerts, result.Alerts...)
+			changes.ImageChanges = append(changes.ImageChanges, result.ImageChanges...)
+			changes.RouteChanges = append(changes.RouteChanges, result.RouteChanges...)
+			for name, change := range result.StatusChanges {
+				changes.StatusChanges[name] = change
+			}
 		}
 	}
 
 	return changes
 }
 
-// getObjectNameNamespace extracts name and namespace from a Kubernetes object
-func getObjectNameNamespace(obj interface{}) (string, string) {
-	if obj == nil {
-		return "", ""
+// addSpecFieldChanges records one SpecChanges entry per changed spec field, keyed by its path
+// (e.g. "spec.rules[0].backendRefs[0].port", or "spec.listeners[https].port" for lists keyed by
+// name, see diffSpec). Falls back to the whole spec if the diff fails
+func addSpecFieldChanges(changes *ChangeDetails, kind string, oldSpec, newSpec map[string]interface{}) {
+	fieldChanges, err := diffSpec(kind, oldSpec, newSpec)
+	if errors.Is(err, ErrDiffTooLarge) {
+		changes.SpecChanges["spec"] = DiffSkippedMarker
+		changes.DiffSkipped = err.Error()
+		return
 	}
-	if objMap, ok := obj.(map[string]interface{}); ok {
-		if metadata, ok := objMap["metadata"].(map[string]interface{}); ok {
-			name, _ := metadata["name"].(string)
-			namespace, _ := metadata["namespace"].(string)
-			return name, namespace
+	if err != nil || len(fieldChanges) == 0 {
+		changes.SpecChanges["spec"] = map[string]interface{}{
+			"old": oldSpec,
+			"new": newSpec,
+		}
+		return
+	}
+
+	for _, fieldChange := range fieldChanges {
+		changes.SpecChanges[joinSpecPath(fieldChange.Path)] = map[string]interface{}{
+			"type": fieldChange.Type,
+			"old":  fieldChange.OldValue,
+			"new":  fieldChange.NewValue,
 		}
 	}
-	return "", ""
+}
+
+// joinSpecPath prefixes a path within the spec with "spec"
+func joinSpecPath(path string) string {
+	if strings.HasPrefix(path, "[") {
+		return "spec" + path
+	}
+	return "spec." + path
 }
 
 // storeVersionedResourceChange stores the full object directly in Redis queue
@@ -213,39 +745,93 @@ func (ep *EventPipeline) storeVersionedResourceChange(event ResourceEvent, oldOb
 
 	resourceKey := fmt.Sprintf("%s/%s/%s", event.ResourceKind, event.Name, event.Namespace)
 
-	// Debug logging
-	fmt.Printf("📊 Generation Check - Resource: %s | Old Gen: %d | New Gen: %d\n", resourceKey, oldGen, newGen)
+	attrs := append(resourceAttrs(event.ResourceKind, event.Namespace, event.Name),
+		slog.String("event_type", string(event.Type)), slog.Int64("generation", newGen))
 
-	// Only store if generation changed or if this is a new object
-	if oldObj != nil && newGen == oldGen {
-		fmt.Printf("⏭️  Skipping - Generation unchanged (still %d)\n\n", newGen)
+	// Debug logging
+	logInfo(fmt.Sprintf("📊 Generation Check - Resource: %s | Old Gen: %d | New Gen: %d", resourceKey, oldGen, newGen),
+		"generation check", append(attrs, slog.Int64("old_generation", oldGen))...)
+
+	// Only store if generation changed or if this is a new object.
+	// Kinds that never set a generation (e.g. ServiceAccount) are stored whenever something changed
+	// Status changes (of kinds watching status) never bump the generation and are stored regardless
+	statusChanged := len(changes.StatusChanges) > 0
+	if oldObj != nil && newGen == oldGen && !(newGen == 0 && changes.HasChanges()) && !statusChanged {
+		logInfo(fmt.Sprintf("⏭️  Skipping - Generation unchanged (still %d)\n", newGen), "skipping, generation unchanged", attrs...)
 		return // Skip storing if generation hasn't changed
 	}
 
-	// Deduplication: check Redis for same resource/generation
-	allObjects, _ := ep.redisManager.GetAllObjects()
-	for _, obj := range allObjects {
-		objKind := getObjectKind(obj)
-		objGen := getObjectGenerationFromEvent(obj)
-		name, ns := getObjectNameNamespace(obj)
-		if objKind == event.ResourceKind && objGen == newGen && name == event.Name && ns == event.Namespace {
-			fmt.Printf("⏭️  Skipping - Duplicate in Redis for %s gen %d\n\n", resourceKey, newGen)
+	// Serialize the dedupe check and push for this resource
+	unlock := ep.redisManager.LockResource(resourceKey)
+	defer unlock()
+
+	// Compare with the resource's newest stored version (meaningless without a generation): skip
+	// a generation it already holds, as after a restart without a restored state, and an older one
+	// arriving late, as from a reconnecting watcher, so the stored history stays in order. A
+	// recreated object (new uid) starts over
+	if newGen > 0 {
+		var uid string
+		if accessor, err := meta.Accessor(event.Object); err == nil {
+			uid = string(accessor.GetUID())
+		}
+		storedGen, storedUID, err := ep.redisManager.LatestGeneration(resourceKey)
+		if err != nil {
+			logWarn(fmt.Sprintf("⚠️  Failed to check for a duplicate in Redis: %v", err),
+				"failed to check for duplicate in Redis", append(attrs, slog.String("error", err.Error()))...)
+		}
+		if storedUID == uid && newGen < storedGen {
+			logInfo(fmt.Sprintf("⏭️  Skipping - Stale gen %d for %s, gen %d already stored\n", newGen, resourceKey, storedGen),
+				"skipping, newer generation already stored", append(attrs, slog.Int64("stored_generation", storedGen))...)
 			return
 		}
+		if storedUID == uid && newGen == storedGen && !statusChanged {
+			logInfo(fmt.Sprintf("⏭️  Skipping - Duplicate in Redis for %s gen %d\n", resourceKey, newGen), "skipping, duplicate in Redis", attrs...)
+			return
+		}
+	}
+
+	// Diff against what is actually stored, so the change record matches what /api/diff reports
+	if ep.changeOutput != nil {
+		fieldChanges, err := ep.redisManager.DiffAgainstPrevious(resourceKey, event.Object)
+		if err != nil {
+			logWarn(fmt.Sprintf("⚠️  Failed to diff against the stored version: %v", err),
+				"failed to diff against stored version", append(attrs, slog.String("error", err.Error()))...)
+		}
+		changes.FieldChanges = fieldChanges
+	}
+
+	// In dry-run mode the decision is logged and the change still reaches handlers and the change output
+	if ep.redisManager.DryRun() {
+		logInfo(fmt.Sprintf("🧪 Dry run - would store object with generation %d\n", newGen), "dry run, not storing object", attrs...)
+		ep.emitChange(event, oldObj, newGen, changes)
+		return
 	}
 
 	// Push object directly to queue
 	if newGen > 0 {
-		fmt.Printf("✅ Storing object with generation %d\n\n", newGen)
-		if err := ep.redisManager.PushObject(resourceKey, event.Object); err != nil {
-			fmt.Printf("⚠️  Failed to store object in queue: %v\n", err)
-		}
+		logInfo(fmt.Sprintf("✅ Storing object with generation %d\n", newGen), "storing object", attrs...)
 	} else {
-		fmt.Printf("ℹ️  No generation found, storing anyway\n\n")
-		if err := ep.redisManager.PushObject(resourceKey, event.Object); err != nil {
-			fmt.Printf("⚠️  Failed to store object in queue: %v\n", err)
-		}
+		logInfo("ℹ️  No generation found, storing anyway\n", "storing object without generation", attrs...)
+	}
+	tags := ObjectTags{RootOwner: event.RootOwner, DiffSkipped: changes.DiffSkipped}
+	if event.CoalescedCount > 1 {
+		tags.CoalescedCount = event.CoalescedCount
+		tags.CoalescedFrom = event.FirstSeen
+		tags.CoalescedUntil = event.Timestamp
+	}
+	if err := ep.redisManager.PushTaggedObject(resourceKey, event.Object, tags); err != nil {
+		logError(fmt.Sprintf("⚠️  Failed to store object in queue: %v", err),
+			"failed to store object", append(attrs, slog.String("error", err.Error()))...)
+		return
+	}
+	recordChangeAuthor(changeAuthor(event.Object), event.ResourceKind)
+
+	// Record the change for the resource's change list and the recent-activity feed (--query)
+	if err := ep.redisManager.pushResourceChange(resourceKey, ep.buildResourceChange(event, oldObj, changes)); err != nil {
+		logError(fmt.Sprintf("⚠️  Failed to store change record: %v", err),
+			"failed to store change record", append(attrs, slog.String("error", err.Error()))...)
 	}
+	ep.emitChange(event, oldObj, newGen, changes)
 }
 
 // getObjectGenerationFromEvent extracts generation number from an object
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// newTestRedisManager returns a RedisManager backed by an in-memory Redis for the test
+func newTestRedisManager(t *testing.T) *RedisManager {
+	t.Helper()
+	server := miniredis.RunT(t)
+	rm, err := NewRedisManager(RedisConfig{Addr: server.Addr()}, "test_changes", 100, 0)
+	if err != nil {
+		t.Fatalf("failed to connect to test Redis: %v", err)
+	}
+	t.Cleanup(func() { rm.Close() })
+	return rm
+}
+
+// newTestRoute returns an HTTPRoute default/web with the given resourceVersion, generation and hostname
+func newTestRoute(resourceVersion string, generation int64, hostname string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "gateway.networking.k8s.io/v1",
+		"kind":       "HTTPRoute",
+		"metadata": map[string]interface{}{
+			"name":            "web",
+			"namespace":       "default",
+			"uid":             "0b7c6a52-3f1e-4c1d-9d55-1f8e2b6c9a01",
+			"resourceVersion": resourceVersion,
+			"generation":      generation,
+		},
+		"spec": map[string]interface{}{
+			"hostnames": []interface{}{hostname},
+		},
+	}}
+}
+
+// routeEvent wraps a test route in a pipeline event
+func routeEvent(eventType EventType, obj *unstructured.Unstructured) ResourceEvent {
+	return ResourceEvent{
+		Type:         eventType,
+		ResourceKind: obj.GetKind(),
+		Namespace:    obj.GetNamespace(),
+		Name:         obj.GetName(),
+		Object:       obj,
+	}
+}
+
+// recordChanges registers a handler on ep collecting the changes it is called with
+func recordChanges(ep *EventPipeline) *[]*ChangeDetails {
+	recorded := make([]*ChangeDetails, 0)
+	ep.RegisterHandler(func(event ResourceEvent, changes *ChangeDetails) {
+		recorded = append(recorded, changes)
+	})
+	return &recorded
+}
+
+func TestRestoreStatesFromRedisDiffsFirstEventAfterRestart(t *testing.T) {
+	// The watch's first event after a restart is a modification, or an ADDED from the initial List
+	for _, eventType := range []EventType{EventTypeModified, EventTypeAdded} {
+		t.Run(string(eventType), func(t *testing.T) {
+			rm := newTestRedisManager(t)
+			before := NewEventPipeline(10, rm)
+			before.processEvent(routeEvent(EventTypeAdded, newTestRoute("100", 1, "old.example.com")))
+
+			// A new pipeline stands in for the restarted process
+			after := NewEventPipeline(10, rm)
+			restored, err := after.RestoreStatesFromRedis()
+			if err != nil {
+				t.Fatalf("RestoreStatesFromRedis: %v", err)
+			}
+			if restored != 1 {
+				t.Fatalf("restored %d states, want 1", restored)
+			}
+			recorded := recordChanges(after)
+
+			after.processEvent(routeEvent(eventType, newTestRoute("101", 2, "new.example.com")))
+
+			if len(*recorded) != 1 {
+				t.Fatalf("handlers called %d times, want 1", len(*recorded))
+			}
+			changes := (*recorded)[0]
+			if changes.OldObject == nil {
+				t.Fatal("change has no old object: the restored state wasn't used")
+			}
+			change, ok := changes.SpecChanges["spec.hostnames[0]"].(map[string]interface{})
+			if !ok {
+				t.Fatalf("spec changes = %v, want a spec.hostnames[0] change", changes.SpecChanges)
+			}
+			if change["old"] != "old.example.com" || change["new"] != "new.example.com" {
+				t.Errorf("spec.hostnames[0] changed %v → %v, want old.example.com → new.example.com", change["old"], change["new"])
+			}
+		})
+	}
+}
+
+func TestWithoutRestoreFirstEventAfterRestartHasNoDiff(t *testing.T) {
+	rm := newTestRedisManager(t)
+
+	before := NewEventPipeline(10, rm)
+	before.processEvent(routeEvent(EventTypeAdded, newTestRoute("100", 1, "old.example.com")))
+
+	after := NewEventPipeline(10, rm)
+	recorded := recordChanges(after)
+	after.processEvent(routeEvent(EventTypeAdded, newTestRoute("101", 2, "new.example.com")))
+
+	if len(*recorded) != 1 {
+		t.Fatalf("handlers called %d times, want 1", len(*recorded))
+	}
+	for path := range (*recorded)[0].SpecChanges {
+		if strings.HasPrefix(path, "spec.") {
+			t.Errorf("unexpected spec change %s without a restored state", path)
+		}
+	}
+}
+
+func TestStoredChangesReachTheChangeLists(t *testing.T) {
+	rm := newTestRedisManager(t)
+	ep := NewEventPipeline(10, rm)
+	ep.processEvent(routeEvent(EventTypeAdded, newTestRoute("100", 1, "old.example.com")))
+	ep.processEvent(routeEvent(EventTypeModified, newTestRoute("101", 2, "new.example.com")))
+
+	changes, err := rm.GetResourceChanges("HTTPRoute/web/default")
+	if err != nil {
+		t.Fatalf("GetResourceChanges: %v", err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("stored %d change records, want 2", len(changes))
+	}
+	if changes[0].Version != 2 || changes[0].ResourceName != "web" {
+		t.Errorf("latest change record is version %d of %q, want version 2 of web", changes[0].Version, changes[0].ResourceName)
+	}
+
+	// --query reads the recent-activity feed
+	recent, err := rm.GetLastNChanges(10)
+	if err != nil {
+		t.Fatalf("GetLastNChanges: %v", err)
+	}
+	if len(recent) != 2 {
+		t.Errorf("recent-activity feed has %d changes, want 2", len(recent))
+	}
+}
+
+func TestReplayedEventIsNotStoredTwice(t *testing.T) {
+	t.Run("same pipeline", func(t *testing.T) {
+		rm := newTestRedisManager(t)
+		ep := NewEventPipeline(10, rm)
+		ep.processEvent(routeEvent(EventTypeAdded, newTestRoute("100", 1, "web.example.com")))
+		ep.processEvent(routeEvent(EventTypeModified, newTestRoute("100", 1, "web.example.com")))
+
+		assertStoredVersions(t, rm, 1)
+	})
+
+	// A restarted process has neither the last resourceVersion nor the previous state, so only
+	// the newest stored version tells the replay apart
+	t.Run("after restart", func(t *testing.T) {
+		rm := newTestRedisManager(t)
+		NewEventPipeline(10, rm).processEvent(routeEvent(EventTypeAdded, newTestRoute("100", 1, "web.example.com")))
+		NewEventPipeline(10, rm).processEvent(routeEvent(EventTypeAdded, newTestRoute("100", 1, "web.example.com")))
+
+		assertStoredVersions(t, rm, 1)
+	})
+
+	t.Run("recreated object", func(t *testing.T) {
+		rm := newTestRedisManager(t)
+		NewEventPipeline(10, rm).processEvent(routeEvent(EventTypeAdded, newTestRoute("100", 1, "web.example.com")))
+		recreated := newTestRoute("200", 1, "web.example.com")
+		recreated.SetUID("5d0e1c7a-8b4f-4a2e-a6c3-2f9b7e1d4c08")
+		NewEventPipeline(10, rm).processEvent(routeEvent(EventTypeAdded, recreated))
+
+		assertStoredVersions(t, rm, 2)
+	})
+}
+
+// assertStoredVersions fails unless the test route's history holds n versions
+func assertStoredVersions(t *testing.T, rm *RedisManager, n int) {
+	t.Helper()
+	history, err := rm.GetResourceObjects("HTTPRoute/web/default")
+	if err != nil {
+		t.Fatalf("GetResourceObjects: %v", err)
+	}
+	if len(history) != n {
+		t.Errorf("stored %d versions, want %d", len(history), n)
+	}
+}
+
+func TestResyncReplayIsNotCountedAsDuplicate(t *testing.T) {
+	rm := newTestRedisManager(t)
+	ep := NewEventPipeline(10, rm)
+	ep.processEvent(routeEvent(EventTypeAdded, newTestRoute("100", 1, "web.example.com")))
+	recorded := recordChanges(ep)
+	duplicates := duplicateEventsMetric.WithLabelValues("HTTPRoute")
+	before := testutil.ToFloat64(duplicates)
+
+	// An informer resync re-sends the cached object with the resourceVersion already processed
+	resync := routeEvent(EventTypeModified, newTestRoute("100", 1, "web.example.com"))
+	resync.Refresh = true
+	ep.processEvent(resync)
+
+	if got := testutil.ToFloat64(duplicates) - before; got != 0 {
+		t.Errorf("resync counted %v duplicate events, want 0", got)
+	}
+	if len(*recorded) != 0 {
+		t.Errorf("handlers called %d times for an unchanged resync, want 0", len(*recorded))
+	}
+	assertStoredVersions(t, rm, 1)
+
+	// A watch replaying the same version is still a duplicate
+	ep.processEvent(routeEvent(EventTypeModified, newTestRoute("100", 1, "web.example.com")))
+	if got := testutil.ToFloat64(duplicates) - before; got != 1 {
+		t.Errorf("replay counted %v duplicate events, want 1", got)
+	}
+}
+
+func TestIsResync(t *testing.T) {
+	cached := newTestRoute("100", 1, "web.example.com")
+	if !isResync(cached, cached.DeepCopy()) {
+		t.Error("an update with the same resourceVersion isn't a resync")
+	}
+	if isResync(cached, newTestRoute("101", 2, "api.example.com")) {
+		t.Error("an update with a new resourceVersion is a resync")
+	}
+}
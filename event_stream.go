@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// streamClientBuffer is how many events a slow SSE client may fall behind before events are dropped for it
+const streamClientBuffer = 64
+
+// StreamEvent is one processed pipeline event as sent to /api/stream clients
+type StreamEvent struct {
+	Type            EventType              `json:"type"`
+	Kind            string                 `json:"kind"`
+	Namespace       string                 `json:"namespace"`
+	Name            string                 `json:"name"`
+	Timestamp       time.Time              `json:"timestamp"`
+	MetadataChanges map[string]interface{} `json:"metadata_changes,omitempty"`
+	SpecChanges     map[string]interface{} `json:"spec_changes,omitempty"`
+	KindChanges     map[string]interface{} `json:"kind_changes,omitempty"`
+	StatusChanges   map[string]interface{} `json:"status_changes,omitempty"`
+	Alerts          []string               `json:"alerts,omitempty"`
+	ImageChanges    []ImageChange          `json:"image_changes,omitempty"`
+	RouteChanges    []string               `json:"route_changes,omitempty"`
+	RootOwner       string                 `json:"root_owner,omitempty"` // followOwned workload owning the object
+	Object          interface{}            `json:"object"`
+}
+
+// streamClient is one connected SSE client and its optional kind filter
+type streamClient struct {
+	events chan []byte
+	kind   string // only events of this kind ("" = all)
+}
+
+// EventBroadcaster fans processed pipeline events out to connected /api/stream clients.
+// Register Handle with the pipeline. A client that can't keep up misses events rather than
+// blocking the pipeline
+type EventBroadcaster struct {
+	clients map[*streamClient]bool
+	mutex   sync.Mutex
+}
+
+// NewEventBroadcaster creates a broadcaster with no clients
+func NewEventBroadcaster() *EventBroadcaster {
+	return &EventBroadcaster{
+		clients: make(map[*streamClient]bool),
+	}
+}
+
+// Subscribe registers a client for events of the given kind ("" for all kinds) and returns
+// its channel of encoded events. Call the returned function to unregister it
+func (eb *EventBroadcaster) Subscribe(kind string) (<-chan []byte, func()) {
+	client := &streamClient{
+		events: make(chan []byte, streamClientBuffer),
+		kind:   kind,
+	}
+
+	eb.mutex.Lock()
+	eb.clients[client] = true
+	eb.mutex.Unlock()
+
+	return client.events, func() {
+		eb.mutex.Lock()
+		delete(eb.clients, client)
+		eb.mutex.Unlock()
+	}
+}
+
+// Handle is a ChangeHandler that sends each processed event to the subscribed clients
+func (eb *EventBroadcaster) Handle(event ResourceEvent, changes *ChangeDetails) {
+	eb.mutex.Lock()
+	defer eb.mutex.Unlock()
+	if len(eb.clients) == 0 {
+		return
+	}
+
+	streamEvent := StreamEvent{
+		Type:      event.Type,
+		Kind:      event.ResourceKind,
+		Namespace: event.Namespace,
+		Name:      event.Name,
+		Timestamp: event.Timestamp,
+		Object:    event.Object,
+		RootOwner: event.RootOwner,
+	}
+	if changes != nil {
+		streamEvent.MetadataChanges = changes.MetadataChanges
+		streamEvent.SpecChanges = changes.SpecChanges
+		streamEvent.KindChanges = changes.KindChanges
+		streamEvent.StatusChanges = changes.StatusChanges
+		streamEvent.Alerts = changes.Alerts
+		streamEvent.ImageChanges = changes.ImageChanges
+		streamEvent.RouteChanges = changes.RouteChanges
+	}
+
+	data, err := json.Marshal(streamEvent)
+	if err != nil {
+		logWarn(fmt.Sprintf("⚠️  Failed to encode stream event for %s %s/%s: %v", event.ResourceKind, event.Namespace, event.Name, err),
+			"failed to encode stream event", append(resourceAttrs(event.ResourceKind, event.Namespace, event.Name), slog.String("error", err.Error()))...)
+		return
+	}
+
+	for client := range eb.clients {
+		if client.kind != "" && client.kind != event.ResourceKind {
+			continue
+		}
+		select {
+		case client.events <- data:
+		default:
+			// Client is too slow; drop rather than stall the pipeline
+		}
+	}
+}
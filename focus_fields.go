@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// focusFieldsKey is the hash holding the latest focus fields of each resource as JSON, so list
+// views can show them without fetching full objects. A hash since keys containing a resource key
+// would match the kind/name/namespace scan pattern
+const focusFieldsKey = "resource_focus_fields"
+
+// defaultFocusFields are the built-in "interesting" fields per kind, as kubectl-style JSONPath
+// templates. A resource's focusFields config replaces the defaults for its kind
+var defaultFocusFields = map[string]map[string]string{
+	"Deployment": {
+		"image":    "{.spec.template.spec.containers[*].image}",
+		"replicas": "{.spec.replicas}",
+	},
+	"StatefulSet": {
+		"image":    "{.spec.template.spec.containers[*].image}",
+		"replicas": "{.spec.replicas}",
+	},
+	"DaemonSet": {
+		"image": "{.spec.template.spec.containers[*].image}",
+	},
+	"Job": {
+		"image": "{.spec.template.spec.containers[*].image}",
+	},
+	"CronJob": {
+		"schedule": "{.spec.schedule}",
+		"image":    "{.spec.jobTemplate.spec.template.spec.containers[*].image}",
+	},
+	"Service": {
+		"type":  "{.spec.type}",
+		"ports": "{.spec.ports[*].port}",
+	},
+	"GatewayClass": {
+		"controller": "{.spec.controllerName}",
+	},
+	"Gateway": {
+		"class":     "{.spec.gatewayClassName}",
+		"listeners": "{.spec.listeners[*].name}",
+	},
+	"HTTPRoute": {
+		"hostnames": "{.spec.hostnames[*]}",
+		"backends":  "{.spec.rules[*].backendRefs[*].name}",
+	},
+	"GRPCRoute": {
+		"services": "{.spec.rules[*].matches[*].method.service}",
+		"backends": "{.spec.rules[*].backendRefs[*].name}",
+	},
+	"TCPRoute": {
+		"backends": "{.spec.rules[*].backendRefs[*].name}",
+	},
+	"ReferenceGrant": {
+		"from": "{.spec.from[*].namespace}",
+		"to":   "{.spec.to[*].kind}",
+	},
+	"Ingress": {
+		"class":    "{.spec.ingressClassName}",
+		"hosts":    "{.spec.rules[*].host}",
+		"backends": "{.spec.rules[*].http.paths[*].backend.service.name}",
+	},
+	"EnvoyProxy": {
+		"provider": "{.spec.provider.type}",
+	},
+	"SecurityPolicy": {
+		"target": "{.spec.targetRefs[*].name}",
+	},
+	"BackendTrafficPolicy": {
+		"target":    "{.spec.targetRefs[*].name}",
+		"rateLimit": "{.spec.rateLimit.type}",
+	},
+	"ClientTrafficPolicy": {
+		"target": "{.spec.targetRefs[*].name}",
+	},
+	"BackendTLSPolicy": {
+		"target": "{.spec.targetRefs[*].name}",
+	},
+}
+
+// FocusFieldSet evaluates per-kind focus fields against objects
+type FocusFieldSet map[string]map[string]*jsonpath.JSONPath
+
+// NewFocusFieldSet compiles the default focus fields, overridden per kind by the focusFields of
+// the configured resources
+func NewFocusFieldSet(resources []ResourceConfig) (FocusFieldSet, error) {
+	templates := make(map[string]map[string]string, len(defaultFocusFields))
+	for kind, fields := range defaultFocusFields {
+		templates[kind] = fields
+	}
+	for _, resource := range resources {
+		if len(resource.FocusFields) > 0 {
+			templates[resource.Kind] = resource.FocusFields
+		}
+	}
+
+	set := make(FocusFieldSet, len(templates))
+	for kind, fields := range templates {
+		set[kind] = make(map[string]*jsonpath.JSONPath, len(fields))
+		for name, template := range fields {
+			parser := jsonpath.New(kind + "." + name).AllowMissingKeys(true)
+			if err := parser.Parse(template); err != nil {
+				return nil, fmt.Errorf("invalid focus field %s for %s: %w", name, kind, err)
+			}
+			set[kind][name] = parser
+		}
+	}
+	return set, nil
+}
+
+// Evaluate returns the focus fields of an object (nil when its kind has none). Fields that
+// resolve to nothing are left out
+func (fs FocusFieldSet) Evaluate(obj interface{}) map[string]string {
+	var u *unstructured.Unstructured
+	switch o := obj.(type) {
+	case *unstructured.Unstructured:
+		u = o
+	case map[string]interface{}:
+		u = &unstructured.Unstructured{Object: o}
+	default:
+		return nil
+	}
+
+	fields, ok := fs[u.GetKind()]
+	if !ok {
+		return nil
+	}
+
+	values := make(map[string]string, len(fields))
+	for name, parser := range fields {
+		var buf bytes.Buffer
+		if err := parser.Execute(&buf, u.Object); err != nil || buf.Len() == 0 {
+			continue
+		}
+		values[name] = buf.String()
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
+
+// SetFocusFields sets the focus fields evaluated for every stored object (nil disables them)
+func (rm *RedisManager) SetFocusFields(fields FocusFieldSet) {
+	rm.focusFields = fields
+}
+
+// saveFocusFields records a resource's latest focus fields for list views
+func (rm *RedisManager) saveFocusFields(ctx context.Context, resourceKey string, values map[string]string) error {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("failed to marshal focus fields: %w", err)
+	}
+	if err := rm.client.HSet(ctx, focusFieldsKey, resourceKey, string(data)).Err(); err != nil {
+		return fmt.Errorf("failed to save focus fields for %s: %w", resourceKey, err)
+	}
+	return nil
+}
+
+// GetFocusFields returns the latest focus fields of the given resources, keyed by resource key.
+// Resources without any are left out
+func (rm *RedisManager) GetFocusFields(resourceKeys []string) (map[string]map[string]string, error) {
+	result := make(map[string]map[string]string)
+	if len(resourceKeys) == 0 {
+		return result, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	values, err := rm.client.HMGet(ctx, focusFieldsKey, resourceKeys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get focus fields: %w", err)
+	}
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue // no focus fields stored for this resource
+		}
+		var fields map[string]string
+		if err := json.Unmarshal([]byte(data), &fields); err != nil {
+			continue // Skip invalid JSON
+		}
+		result[resourceKeys[i]] = fields
+	}
+	return result, nil
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// FullObjectPolicy returns how often a full object is stored per kind, for the resources that
+// set fullObjectEvery above 1
+func (wc *WatcherConfig) FullObjectPolicy() map[string]int {
+	policy := make(map[string]int)
+	for _, resource := range wc.GetEnabledResources() {
+		if resource.FullObjectEvery > 1 {
+			policy[resource.Kind] = resource.FullObjectEvery
+		}
+	}
+	return policy
+}
+
+// SetFullObjectPolicy sets, per kind, that only every Kth stored version holds the full object.
+// The versions in between store the object's metadata plus a JSON merge patch against the
+// previous version, and are reconstructed when history is read. Kinds not listed always store
+// full objects
+func (rm *RedisManager) SetFullObjectPolicy(policy map[string]int) {
+	rm.fullObjectEvery = policy
+}
+
+// applyFullObjectPolicy turns a version about to be pushed into a diff entry when its kind's
+// policy allows it. It returns the raw entry that has to replace the oldest version kept after
+// trimming ("" if none): that version must hold a full object since its own base is trimmed away
+func (rm *RedisManager) applyFullObjectPolicy(ctx context.Context, resourceKey string, storedObj *StoredObject) (string, error) {
+	kind, _, _ := strings.Cut(resourceKey, "/")
+	every := rm.fullObjectEvery[kind]
+	if every <= 1 {
+		return "", nil
+	}
+
+	raw, err := rm.client.LRange(ctx, resourceKey, 0, -1).Result()
+	if err != nil {
+		return "", fmt.Errorf("failed to get history from resource key %s: %w", resourceKey, err)
+	}
+	history := decodeHistory(raw)
+	isDiff := make([]bool, len(history))
+	for i, entry := range history {
+		isDiff[i] = isDiffEntry(entry)
+	}
+	reconstructHistory(history)
+
+	// Versions stored since the last full object
+	sinceFull := 0
+	for sinceFull < len(isDiff) && isDiff[sinceFull] {
+		sinceFull++
+	}
+
+	if !storedObj.Baseline && len(history) > 0 && !isDiffEntry(history[0]) && sinceFull < every-1 {
+		if entry, ok := history[0].(map[string]interface{}); ok {
+			if base, ok := entry["object"].(map[string]interface{}); ok {
+				current, err := toJSONMap(storedObj.Object)
+				if err != nil {
+					return "", err
+				}
+				storedObj.Patch = createMergePatch(base, current)
+				storedObj.Object = objectMetadata(current)
+			}
+		}
+	}
+
+	oldest := min(len(history), rm.maxSize-1) - 1
+	if oldest < 0 || !isDiff[oldest] || isDiffEntry(history[oldest]) {
+		return "", nil // already full, or not reconstructable
+	}
+	data, err := json.Marshal(history[oldest])
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal full version of %s: %w", resourceKey, err)
+	}
+	return string(data), nil
+}
+
+// decodeHistory decodes raw history entries, leaving nil for entries that aren't valid JSON
+func decodeHistory(raw []string) []interface{} {
+	history := make([]interface{}, len(raw))
+	for i, entry := range raw {
+		var obj interface{}
+		if err := json.Unmarshal([]byte(entry), &obj); err == nil {
+			history[i] = obj
+		}
+	}
+	return history
+}
+
+// isDiffEntry reports whether a decoded history entry stores a diff rather than a full object
+func isDiffEntry(entry interface{}) bool {
+	entryMap, ok := entry.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	_, hasPatch := entryMap["patch"]
+	return hasPatch
+}
+
+// reconstructHistory replaces the diff entries of a decoded history (most recent first) with the
+// full objects they describe, in place. Diffs whose base version is missing are left as they are
+func reconstructHistory(history []interface{}) {
+	var previous map[string]interface{}
+	for i := len(history) - 1; i >= 0; i-- {
+		entry, ok := history[i].(map[string]interface{})
+		if !ok {
+			previous = nil
+			continue
+		}
+		patch, isDiff := entry["patch"].(map[string]interface{})
+		if !isDiff {
+			if _, hasPatch := entry["patch"]; hasPatch {
+				previous = nil // unreadable diff, later ones can't be rebuilt either
+				continue
+			}
+			previous, _ = entry["object"].(map[string]interface{})
+			continue
+		}
+		if previous == nil {
+			continue
+		}
+		previous = applyMergePatch(previous, patch)
+		entry["object"] = previous
+		delete(entry, "patch")
+	}
+}
+
+// toJSONMap converts an object to the map form it takes when decoded from storage
+func toJSONMap(obj interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal object: %w", err)
+	}
+	var objMap map[string]interface{}
+	if err := json.Unmarshal(data, &objMap); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal object: %w", err)
+	}
+	return objMap, nil
+}
+
+// objectMetadata keeps only the type and metadata of an object, what a diff entry stores in
+// place of the full object so it still identifies the version
+func objectMetadata(obj map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, 3)
+	for _, key := range []string{"apiVersion", "kind", "metadata"} {
+		if value, ok := obj[key]; ok {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// createMergePatch returns the JSON merge patch (RFC 7386) turning old into new. Lists are
+// replaced as a whole, and a field set to null is treated as removed
+func createMergePatch(old, new map[string]interface{}) map[string]interface{} {
+	patch := make(map[string]interface{})
+	for key, oldValue := range old {
+		newValue, ok := new[key]
+		if !ok {
+			patch[key] = nil
+			continue
+		}
+		oldMap, oldIsMap := oldValue.(map[string]interface{})
+		newMap, newIsMap := newValue.(map[string]interface{})
+		if oldIsMap && newIsMap {
+			if nested := createMergePatch(oldMap, newMap); len(nested) > 0 {
+				patch[key] = nested
+			}
+			continue
+		}
+		if !reflect.DeepEqual(oldValue, newValue) {
+			patch[key] = newValue
+		}
+	}
+	for key, newValue := range new {
+		if _, ok := old[key]; !ok {
+			patch[key] = newValue
+		}
+	}
+	return patch
+}
+
+// applyMergePatch returns a copy of doc with a JSON merge patch applied
+func applyMergePatch(doc, patch map[string]interface{}) map[string]interface{} {
+	result := runtime.DeepCopyJSON(doc)
+	mergePatchInto(result, patch)
+	return result
+}
+
+// mergePatchInto applies a JSON merge patch to target in place
+func mergePatchInto(target, patch map[string]interface{}) {
+	for key, value := range patch {
+		switch v := value.(type) {
+		case nil:
+			delete(target, key)
+		case map[string]interface{}:
+			existing, ok := target[key].(map[string]interface{})
+			if !ok {
+				existing = make(map[string]interface{}, len(v))
+				target[key] = existing
+			}
+			mergePatchInto(existing, v)
+		default:
+			target[key] = runtime.DeepCopyJSONValue(v)
+		}
+	}
+}
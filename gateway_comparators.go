@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// compareBackendTLSPolicies highlights changes to spec.validation (CA certificate refs,
+// hostname, well-known CA certificates) and to the policy's target refs
+func compareBackendTLSPolicies(old, new *unstructured.Unstructured) *ComparatorResult {
+	result := newComparatorResult()
+
+	oldTargets, _, _ := unstructured.NestedSlice(old.Object, "spec", "targetRefs")
+	newTargets, _, _ := unstructured.NestedSlice(new.Object, "spec", "targetRefs")
+	if !reflect.DeepEqual(oldTargets, newTargets) {
+		result.addChange("spec.targetRefs", oldTargets, newTargets)
+	}
+
+	oldValidation, _, _ := unstructured.NestedMap(old.Object, "spec", "validation")
+	newValidation, _, _ := unstructured.NestedMap(new.Object, "spec", "validation")
+
+	for _, field := range []string{"caCertificateRefs", "hostname", "wellKnownCACertificates", "subjectAltNames"} {
+		if !reflect.DeepEqual(oldValidation[field], newValidation[field]) {
+			result.addChange("spec.validation."+field, oldValidation[field], newValidation[field])
+		}
+	}
+
+	if oldHost, newHost := oldValidation["hostname"], newValidation["hostname"]; oldHost != nil && !reflect.DeepEqual(oldHost, newHost) {
+		result.Alerts = append(result.Alerts,
+			fmt.Sprintf("BackendTLSPolicy %s/%s: backend TLS hostname changed %v → %v",
+				new.GetNamespace(), new.GetName(), oldHost, newHost))
+	}
+
+	return result
+}
+
+// compareGatewayClasses reports changes to a GatewayClass's Accepted condition. A GatewayClass
+// its controller doesn't accept silently stops every Gateway using it from being programmed,
+// so a flip to False is raised as an alert with the controller's reason
+func compareGatewayClasses(old, new *unstructured.Unstructured) *ComparatorResult {
+	result := newComparatorResult()
+
+	oldAccepted, _ := findCondition(old, "Accepted")
+	newAccepted, found := findCondition(new, "Accepted")
+	oldStatus, _ := oldAccepted["status"].(string)
+	newStatus, _ := newAccepted["status"].(string)
+	if oldStatus != newStatus {
+		result.addChange("status.conditions[Accepted]", oldStatus, newStatus)
+	}
+
+	if found && newStatus == "False" && oldStatus != "False" {
+		controller, _, _ := unstructured.NestedString(new.Object, "spec", "controllerName")
+		reason, _ := newAccepted["reason"].(string)
+		message, _ := newAccepted["message"].(string)
+		result.Alerts = append(result.Alerts,
+			fmt.Sprintf("GatewayClass %s: no longer Accepted by controller %s (reason %s: %s) - Gateways using it won't be programmed",
+				new.GetName(), controller, reason, message))
+	}
+
+	return result
+}
+
+// compareGateways reports changes to the Gateway's and each listener's status conditions.
+// A Gateway or listener that stops being Programmed or Accepted is raised as an alert, since
+// that's how a Gateway going unhealthy shows up
+func compareGateways(old, new *unstructured.Unstructured) *ComparatorResult {
+	result := newComparatorResult()
+	where := fmt.Sprintf("Gateway %s/%s", new.GetNamespace(), new.GetName())
+
+	oldConditions, _, _ := unstructured.NestedSlice(old.Object, "status", "conditions")
+	newConditions, _, _ := unstructured.NestedSlice(new.Object, "status", "conditions")
+	result.diffConditions("status.conditions", where, oldConditions, newConditions, "Programmed", "Accepted")
+
+	oldListeners, _, _ := unstructured.NestedSlice(old.Object, "status", "listeners")
+	newListeners, _, _ := unstructured.NestedSlice(new.Object, "status", "listeners")
+	oldByName, _ := listItemsByName(oldListeners)
+	newByName, newOrder := listItemsByName(newListeners)
+	for _, name := range newOrder {
+		oldListenerConditions, _, _ := unstructured.NestedSlice(oldByName[name], "conditions")
+		newListenerConditions, _, _ := unstructured.NestedSlice(newByName[name], "conditions")
+		result.diffConditions(fmt.Sprintf("status.listeners[%s].conditions", name), fmt.Sprintf("%s listener %s", where, name),
+			oldListenerConditions, newListenerConditions, "Programmed", "Accepted")
+	}
+
+	return result
+}
+
+// compareHTTPRoutes reports changes to the route's status conditions for each parent (Gateway).
+// A route that stops being Accepted or whose refs stop resolving is raised as an alert
+func compareHTTPRoutes(old, new *unstructured.Unstructured) *ComparatorResult {
+	result := newComparatorResult()
+	where := fmt.Sprintf("HTTPRoute %s/%s", new.GetNamespace(), new.GetName())
+
+	oldParents, _, _ := unstructured.NestedSlice(old.Object, "status", "parents")
+	newParents, _, _ := unstructured.NestedSlice(new.Object, "status", "parents")
+	oldByParent := routeParentStatusesByName(oldParents)
+	for _, item := range newParents {
+		parentStatus, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		parent := routeParentName(parentStatus)
+		oldConditions, _, _ := unstructured.NestedSlice(oldByParent[parent], "conditions")
+		newConditions, _, _ := unstructured.NestedSlice(parentStatus, "conditions")
+		result.diffConditions(fmt.Sprintf("status.parents[%s].conditions", parent), fmt.Sprintf("%s on %s", where, parent),
+			oldConditions, newConditions, "Accepted", "ResolvedRefs")
+	}
+
+	return result
+}
+
+// diffConditions records a status change for each condition type whose status or reason changed,
+// keyed "<prefix>[<type>]". A watched condition type going from True to anything else is alerted on
+func (cr *ComparatorResult) diffConditions(prefix, where string, oldConditions, newConditions []interface{}, watchedTypes ...string) {
+	oldByType := conditionsByType(oldConditions)
+	newByType := conditionsByType(newConditions)
+
+	for _, conditionType := range unionKeys(oldByType, newByType) {
+		oldCondition, _ := oldByType[conditionType].(map[string]interface{})
+		newCondition, _ := newByType[conditionType].(map[string]interface{})
+		oldStatus, _ := oldCondition["status"].(string)
+		newStatus, _ := newCondition["status"].(string)
+		oldReason, _ := oldCondition["reason"].(string)
+		newReason, _ := newCondition["reason"].(string)
+		if oldStatus == newStatus && oldReason == newReason {
+			continue
+		}
+
+		cr.StatusChanges[fmt.Sprintf("%s[%s]", prefix, conditionType)] = map[string]interface{}{
+			"old": map[string]interface{}{"status": oldStatus, "reason": oldReason},
+			"new": map[string]interface{}{"status": newStatus, "reason": newReason},
+		}
+
+		if oldStatus == "True" && newStatus != "True" && slices.Contains(watchedTypes, conditionType) {
+			message, _ := newCondition["message"].(string)
+			cr.Alerts = append(cr.Alerts,
+				fmt.Sprintf("%s: no longer %s (status %q, reason %s: %s)", where, conditionType, newStatus, newReason, message))
+		}
+	}
+}
+
+// conditionsByType indexes a conditions list by condition type
+func conditionsByType(conditions []interface{}) map[string]interface{} {
+	byType := make(map[string]interface{}, len(conditions))
+	for _, item := range conditions {
+		if condition, ok := item.(map[string]interface{}); ok {
+			if conditionType, ok := condition["type"].(string); ok {
+				byType[conditionType] = condition
+			}
+		}
+	}
+	return byType
+}
+
+// routeParentStatusesByName indexes a route's status.parents by parent name
+func routeParentStatusesByName(parents []interface{}) map[string]map[string]interface{} {
+	byName := make(map[string]map[string]interface{}, len(parents))
+	for _, item := range parents {
+		if parentStatus, ok := item.(map[string]interface{}); ok {
+			byName[routeParentName(parentStatus)] = parentStatus
+		}
+	}
+	return byName
+}
+
+// routeParentName identifies a route parent status by its parentRef as [namespace/]name[:section]
+func routeParentName(parentStatus map[string]interface{}) string {
+	name, _, _ := unstructured.NestedString(parentStatus, "parentRef", "name")
+	if namespace, _, _ := unstructured.NestedString(parentStatus, "parentRef", "namespace"); namespace != "" {
+		name = namespace + "/" + name
+	}
+	if section, _, _ := unstructured.NestedString(parentStatus, "parentRef", "sectionName"); section != "" {
+		name += ":" + section
+	}
+	return name
+}
+
+// findCondition returns the status.conditions entry of the given type
+func findCondition(obj *unstructured.Unstructured, conditionType string) (map[string]interface{}, bool) {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, item := range conditions {
+		condition, ok := item.(map[string]interface{})
+		if ok && condition["type"] == conditionType {
+			return condition, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// grpcRouteTarget is where a GRPCRoute sends the calls of one match
+type grpcRouteTarget struct {
+	backends string // e.g. "api:50051, api-canary:50051 (weight 10)"
+	filters  string // filter types, e.g. "RequestHeaderModifier"
+}
+
+// compareGRPCRoutes reports changes to a GRPCRoute's method matches, and to the backends and
+// filters of the rule each match belongs to, as route changes like
+// "added match Greeter/SayHello → backend api:50051". Status conditions are compared per
+// parent as for HTTPRoutes
+func compareGRPCRoutes(old, new *unstructured.Unstructured) *ComparatorResult {
+	result := newComparatorResult()
+
+	oldTargets, oldOrder := grpcRouteTargets(old)
+	newTargets, newOrder := grpcRouteTargets(new)
+
+	for _, match := range newOrder {
+		newTarget := newTargets[match]
+		oldTarget, existed := oldTargets[match]
+		if !existed {
+			result.addChange(fmt.Sprintf("spec.rules[%s]", match), nil, newTarget.backends)
+			result.RouteChanges = append(result.RouteChanges,
+				fmt.Sprintf("added match %s → %s", match, describeGRPCBackends(newTarget.backends)))
+			continue
+		}
+		if oldTarget.backends != newTarget.backends {
+			result.addChange(fmt.Sprintf("spec.rules[%s].backendRefs", match), oldTarget.backends, newTarget.backends)
+			result.RouteChanges = append(result.RouteChanges,
+				fmt.Sprintf("match %s: %s → %s", match, describeGRPCBackends(oldTarget.backends), orNone(newTarget.backends)))
+		}
+		if oldTarget.filters != newTarget.filters {
+			result.addChange(fmt.Sprintf("spec.rules[%s].filters", match), oldTarget.filters, newTarget.filters)
+			result.RouteChanges = append(result.RouteChanges,
+				fmt.Sprintf("match %s: filters %s → %s", match, orNone(oldTarget.filters), orNone(newTarget.filters)))
+		}
+	}
+	for _, match := range oldOrder {
+		if _, exists := newTargets[match]; !exists {
+			result.addChange(fmt.Sprintf("spec.rules[%s]", match), oldTargets[match].backends, nil)
+			result.RouteChanges = append(result.RouteChanges,
+				fmt.Sprintf("removed match %s (was → %s)", match, describeGRPCBackends(oldTargets[match].backends)))
+		}
+	}
+
+	where := fmt.Sprintf("GRPCRoute %s/%s", new.GetNamespace(), new.GetName())
+	oldParents, _, _ := unstructured.NestedSlice(old.Object, "status", "parents")
+	newParents, _, _ := unstructured.NestedSlice(new.Object, "status", "parents")
+	oldByParent := routeParentStatusesByName(oldParents)
+	for _, item := range newParents {
+		parentStatus, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		parent := routeParentName(parentStatus)
+		oldConditions, _, _ := unstructured.NestedSlice(oldByParent[parent], "conditions")
+		newConditions, _, _ := unstructured.NestedSlice(parentStatus, "conditions")
+		result.diffConditions(fmt.Sprintf("status.parents[%s].conditions", parent), fmt.Sprintf("%s on %s", where, parent),
+			oldConditions, newConditions, "Accepted", "ResolvedRefs")
+	}
+
+	return result
+}
+
+// grpcRouteTargets indexes a GRPCRoute's rules by match description, in rule order. A rule
+// without matches matches every method ("*/*"). When two rules share a match the first wins,
+// as it does for the Gateway
+func grpcRouteTargets(route *unstructured.Unstructured) (map[string]grpcRouteTarget, []string) {
+	targets := make(map[string]grpcRouteTarget)
+	order := make([]string, 0)
+
+	rules, _, _ := unstructured.NestedSlice(route.Object, "spec", "rules")
+	for _, item := range rules {
+		rule, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		backendRefs, _, _ := unstructured.NestedSlice(rule, "backendRefs")
+		filters, _, _ := unstructured.NestedSlice(rule, "filters")
+		target := grpcRouteTarget{
+			backends: grpcBackendRefs(backendRefs),
+			filters:  filterTypes(filters),
+		}
+
+		matches, _, _ := unstructured.NestedSlice(rule, "matches")
+		descriptions := make([]string, 0, len(matches))
+		for _, matchItem := range matches {
+			if match, ok := matchItem.(map[string]interface{}); ok {
+				descriptions = append(descriptions, grpcMethodMatch(match))
+			}
+		}
+		if len(descriptions) == 0 {
+			descriptions = append(descriptions, "*/*")
+		}
+
+		for _, description := range descriptions {
+			if _, exists := targets[description]; exists {
+				continue
+			}
+			targets[description] = target
+			order = append(order, description)
+		}
+	}
+	return targets, order
+}
+
+// grpcMethodMatch describes a GRPCRoute match as service/method ("*" for an unset part), with
+// a "~" prefix for regular expressions and any header matches appended
+func grpcMethodMatch(match map[string]interface{}) string {
+	service, _, _ := unstructured.NestedString(match, "method", "service")
+	method, _, _ := unstructured.NestedString(match, "method", "method")
+	matchType, _, _ := unstructured.NestedString(match, "method", "type")
+	if service == "" {
+		service = "*"
+	}
+	if method == "" {
+		method = "*"
+	}
+
+	description := service + "/" + method
+	if matchType == "RegularExpression" {
+		description = "~" + description
+	}
+
+	headers, _, _ := unstructured.NestedSlice(match, "headers")
+	conditions := make([]string, 0, len(headers))
+	for _, item := range headers {
+		if header, ok := item.(map[string]interface{}); ok {
+			name, _ := header["name"].(string)
+			value, _ := header["value"].(string)
+			conditions = append(conditions, name+"="+value)
+		}
+	}
+	if len(conditions) > 0 {
+		description += " [" + strings.Join(conditions, ", ") + "]"
+	}
+	return description
+}
+
+// grpcBackendRefs describes backend refs as [namespace/]name:port, with non-default weights
+func grpcBackendRefs(backendRefs []interface{}) string {
+	backends := make([]string, 0, len(backendRefs))
+	for _, item := range backendRefs {
+		ref, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := ref["name"].(string)
+		if namespace, _ := ref["namespace"].(string); namespace != "" {
+			name = namespace + "/" + name
+		}
+		if port, ok := ref["port"]; ok {
+			name = fmt.Sprintf("%s:%v", name, port)
+		}
+		if weight, ok := ref["weight"]; ok && !numbersEqual(weight, int64(1)) {
+			name = fmt.Sprintf("%s (weight %v)", name, weight)
+		}
+		backends = append(backends, name)
+	}
+	return strings.Join(backends, ", ")
+}
+
+// filterTypes lists the types of a rule's filters
+func filterTypes(filters []interface{}) string {
+	types := make([]string, 0, len(filters))
+	for _, item := range filters {
+		if filter, ok := item.(map[string]interface{}); ok {
+			filterType, _ := filter["type"].(string)
+			types = append(types, filterType)
+		}
+	}
+	return strings.Join(types, ", ")
+}
+
+// describeGRPCBackends prefixes a backend list with "backend" or "backends"
+func describeGRPCBackends(backends string) string {
+	switch {
+	case backends == "":
+		return "no backends"
+	case strings.Contains(backends, ", "):
+		return "backends " + backends
+	}
+	return "backend " + backends
+}
+
+// orNone returns "none" for an empty description
+func orNone(description string) string {
+	if description == "" {
+		return "none"
+	}
+	return description
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Archive formats supported by /api/export
+const (
+	ExportFormatZip   = "zip"
+	ExportFormatTarGz = "tar.gz"
+)
+
+// exportEntry is one file of a history export
+type exportEntry struct {
+	name     string
+	modified time.Time
+	content  []byte
+}
+
+// historyExportEntries converts a resource's stored history (most recent first) into one YAML
+// file per version, oldest first. A generation stored more than once (metadata-only changes,
+// baselines) gets a numbered suffix: gen-3.yaml, gen-3-2.yaml, ...
+func historyExportEntries(objects []interface{}, opts CleanOptions) ([]exportEntry, error) {
+	entries := make([]exportEntry, 0, len(objects))
+	seen := make(map[int64]int)
+
+	for i := len(objects) - 1; i >= 0; i-- {
+		obj := objects[i]
+		generation := getObjectGeneration(obj)
+		yamlString, err := ConvertToYAMLWithOptions(unwrapStoredObject(obj), opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert generation %d to YAML: %w", generation, err)
+		}
+
+		seen[generation]++
+		name := fmt.Sprintf("gen-%d.yaml", generation)
+		if seen[generation] > 1 {
+			name = fmt.Sprintf("gen-%d-%d.yaml", generation, seen[generation])
+		}
+
+		modified, err := time.Parse(time.RFC3339, getObjectTimestamp(obj))
+		if err != nil {
+			modified = time.Now()
+		}
+		entries = append(entries, exportEntry{name: name, modified: modified, content: []byte(yamlString)})
+	}
+	return entries, nil
+}
+
+// writeExportArchive writes the entries to w as a zip or gzipped tar archive, one file at a time
+func writeExportArchive(w io.Writer, format string, entries []exportEntry) error {
+	switch format {
+	case ExportFormatZip:
+		return writeZipArchive(w, entries)
+	case ExportFormatTarGz:
+		return writeTarGzArchive(w, entries)
+	}
+	return fmt.Errorf("unknown export format %q (expected %s or %s)", format, ExportFormatZip, ExportFormatTarGz)
+}
+
+// writeZipArchive writes the entries as a deflated zip archive
+func writeZipArchive(w io.Writer, entries []exportEntry) error {
+	archive := zip.NewWriter(w)
+	for _, entry := range entries {
+		file, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     entry.name,
+			Method:   zip.Deflate,
+			Modified: entry.modified,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add %s: %w", entry.name, err)
+		}
+		if _, err := file.Write(entry.content); err != nil {
+			return fmt.Errorf("failed to write %s: %w", entry.name, err)
+		}
+	}
+	return archive.Close()
+}
+
+// writeTarGzArchive writes the entries as a gzipped tar archive
+func writeTarGzArchive(w io.Writer, entries []exportEntry) error {
+	compressed := gzip.NewWriter(w)
+	archive := tar.NewWriter(compressed)
+	for _, entry := range entries {
+		if err := archive.WriteHeader(&tar.Header{
+			Name:    entry.name,
+			Mode:    0644,
+			Size:    int64(len(entry.content)),
+			ModTime: entry.modified,
+		}); err != nil {
+			return fmt.Errorf("failed to add %s: %w", entry.name, err)
+		}
+		if _, err := archive.Write(entry.content); err != nil {
+			return fmt.Errorf("failed to write %s: %w", entry.name, err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return err
+	}
+	return compressed.Close()
+}
@@ -2,10 +2,17 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"sigs.k8s.io/yaml"
 )
 
 // HTTPResponse is a generic response wrapper
@@ -16,41 +23,208 @@ type HTTPResponse struct {
 	Error   string      `json:"error,omitempty"`
 }
 
-// StartHTTPServer starts the HTTP server with the three main APIs
-func StartHTTPServer(redisManager *RedisManager, port string) error {
+// HTTPServerConfig configures the HTTP server
+type HTTPServerConfig struct {
+	Port            string
+	WatcherManager  *WatcherManager   // enables admin endpoints when non-nil
+	Pipeline        *EventPipeline    // source of runtime details for /api/health/details
+	ScanConcurrency int               // max concurrent requests running Redis key scans
+	ScanBudget      int               // max keys a single request may scan before returning partial results
+	Broadcaster     *EventBroadcaster // source of live events for /api/stream
+
+	ClusterScopedKinds map[string]bool // kinds stored without a namespace, queried with namespace omitted, see ClusterScopedKinds
+}
+
+// resourceScopes knows which kinds are cluster-scoped, so their resources can be queried without a namespace
+type resourceScopes map[string]bool
+
+// missingParams reports whether kind, name or a required namespace is empty. Cluster-scoped
+// kinds are stored as "Kind/name/", so they are queried with an empty namespace
+func (rs resourceScopes) missingParams(kind, name, namespace string) bool {
+	return kind == "" || name == "" || (namespace == "" && !rs[kind])
+}
+
+// scanLimiter bounds concurrent scan-heavy requests so they don't saturate the Redis pool
+type scanLimiter struct {
+	slots  chan struct{}
+	budget int
+}
+
+// acquire waits for a scan slot; returns false if the client went away first
+func (sl *scanLimiter) acquire(r *http.Request) bool {
+	select {
+	case sl.slots <- struct{}{}:
+		return true
+	case <-r.Context().Done():
+		return false
+	}
+}
+
+// release frees a scan slot
+func (sl *scanLimiter) release() {
+	<-sl.slots
+}
+
+// StartHTTPServer starts the HTTP server with the main APIs.
+// Admin endpoints are only registered when config.WatcherManager is non-nil.
+// A nil redisManager means degraded mode: storage-backed APIs answer 503 and /health reports degraded
+func StartHTTPServer(redisManager *RedisManager, config HTTPServerConfig) error {
+	port := config.Port
+	watcherManager := config.WatcherManager
+
+	if config.ScanConcurrency < 1 {
+		config.ScanConcurrency = 1
+	}
+	scans := &scanLimiter{
+		slots:  make(chan struct{}, config.ScanConcurrency),
+		budget: config.ScanBudget,
+	}
+	scopes := resourceScopes(config.ClusterScopedKinds)
+
 	// API 1: Get resource history (generations & timestamps)
-	http.HandleFunc("/api/history", func(w http.ResponseWriter, r *http.Request) {
-		handleGetResourceHistory(w, r, redisManager)
-	})
+	http.HandleFunc("/api/history", requireStorage(redisManager, func(w http.ResponseWriter, r *http.Request) {
+		handleGetResourceHistory(w, r, redisManager, scopes)
+	}))
 
 	// API 2: Get specific generation YAML
-	http.HandleFunc("/api/generation", func(w http.ResponseWriter, r *http.Request) {
-		handleGetGenerationYAML(w, r, redisManager)
-	})
+	http.HandleFunc("/api/generation", requireStorage(redisManager, func(w http.ResponseWriter, r *http.Request) {
+		handleGetGenerationYAML(w, r, redisManager, scopes)
+	}))
 
 	// API 3: List all resource tuples
-	http.HandleFunc("/api/resources", func(w http.ResponseWriter, r *http.Request) {
-		handleListAllResources(w, r, redisManager)
-	})
+	http.HandleFunc("/api/resources", requireStorage(redisManager, func(w http.ResponseWriter, r *http.Request) {
+		handleListAllResources(w, r, redisManager, scans)
+	}))
+
+	// API 4: Compare the latest versions of two different resources
+	http.HandleFunc("/api/compare", requireStorage(redisManager, func(w http.ResponseWriter, r *http.Request) {
+		handleCompareResources(w, r, redisManager, scopes)
+	}))
+
+	// API 5: Diff two stored versions of the same resource
+	http.HandleFunc("/api/diff", requireStorage(redisManager, func(w http.ResponseWriter, r *http.Request) {
+		handleDiff(w, r, redisManager, scopes)
+	}))
+
+	// API 6: Summarize stored changes per field manager over a time window
+	http.HandleFunc("/api/authors", requireStorage(redisManager, func(w http.ResponseWriter, r *http.Request) {
+		handleAuthors(w, r, redisManager, scans)
+	}))
+
+	// API 7: Get all changes sharing a correlation ID (e.g. one CD run)
+	http.HandleFunc("/api/by-correlation", requireStorage(redisManager, func(w http.ResponseWriter, r *http.Request) {
+		handleByCorrelation(w, r, redisManager)
+	}))
+
+	// API 8: Download a resource's whole history as an archive of per-generation YAML files
+	http.HandleFunc("/api/export", requireStorage(redisManager, func(w http.ResponseWriter, r *http.Request) {
+		handleExport(w, r, redisManager, scopes)
+	}))
+
+	// API 9: Get the YAML of the newest stored version
+	http.HandleFunc("/api/latest", requireStorage(redisManager, func(w http.ResponseWriter, r *http.Request) {
+		handleGetLatestYAML(w, r, redisManager, scopes)
+	}))
+
+	// Live stream of processed events (Server-Sent Events); works without Redis
+	if config.Broadcaster != nil {
+		http.HandleFunc("/api/stream", func(w http.ResponseWriter, r *http.Request) {
+			handleStream(w, r, config.Broadcaster)
+		})
+	}
+
+	// Admin: Force a re-list and reconcile of a resource type
+	if watcherManager != nil {
+		http.HandleFunc("/api/resync", func(w http.ResponseWriter, r *http.Request) {
+			handleResync(w, r, watcherManager)
+		})
+
+		// Admin: Re-apply a stored generation of a resource
+		http.HandleFunc("/api/rollback", requireStorage(redisManager, func(w http.ResponseWriter, r *http.Request) {
+			handleRollback(w, r, redisManager, watcherManager, scopes)
+		}))
+	}
 
 	// Health check endpoint
 	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
+		if redisManager == nil {
+			json.NewEncoder(w).Encode(HTTPResponse{
+				Success: true,
+				Message: "Server is degraded: Redis unavailable, storage disabled",
+				Data:    map[string]string{"status": "degraded", "storage": "disabled"},
+			})
+			return
+		}
 		json.NewEncoder(w).Encode(HTTPResponse{
 			Success: true,
 			Message: "Server is healthy",
 		})
 	})
 
-	fmt.Printf("🌐 HTTP Server starting on :%s\n", port)
-	fmt.Printf("   📍 GET /api/history?kind=<KIND>&name=<NAME>&namespace=<NS> - Get resource history\n")
-	fmt.Printf("   📍 GET /api/generation?kind=<KIND>&name=<NAME>&namespace=<NS>&generation=<GEN> - Get specific generation\n")
-	fmt.Printf("   📍 GET /api/resources - List all resources\n")
-	fmt.Printf("   📍 GET /health - Health check\n\n")
+	// Prometheus metrics
+	if config.Pipeline != nil {
+		RegisterPipelineMetrics(config.Pipeline)
+	}
+	http.Handle("/metrics", promhttp.Handler())
+
+	// Detailed runtime health (throttled resources, pipeline backlog)
+	http.HandleFunc("/api/health/details", func(w http.ResponseWriter, r *http.Request) {
+		handleHealthDetails(w, r, config.Pipeline)
+	})
+
+	// Built-in dashboard browsing resources, history and diffs
+	http.HandleFunc("/ui", handleDashboard)
+	http.HandleFunc("/", handleRoot)
+
+	routes := []string{
+		"   📍 GET /api/history?kind=<KIND>&name=<NAME>&namespace=<NS>[&limit=<N>&offset=<N>] - Get resource history",
+		"   📍 GET /api/generation?kind=<KIND>&name=<NAME>&namespace=<NS>&generation=<GEN> - Get specific generation",
+		"   📍 GET /api/latest?kind=<KIND>&name=<NAME>&namespace=<NS> - Get the newest stored version",
+		"   📍 GET /api/resources - List all resources",
+		"   📍 GET /ui - Dashboard browsing resources, history and diffs",
+		"   📍 GET /api/compare?kindA=<KIND>&nameA=<NAME>&namespaceA=<NS>&kindB=<KIND>&nameB=<NAME>&namespaceB=<NS> - Compare two resources",
+		"   📍 GET /api/diff?kind=<KIND>&name=<NAME>&namespace=<NS>[&from=<GEN>&to=<GEN>&format=json|ascii|markdown|jsonpatch] - Diff two versions",
+		"   📍 GET /api/authors[?window=<DURATION>&kind=<KIND>] - Change counts per field manager",
+		"   📍 GET /api/by-correlation?id=<ID> - Changes sharing a correlation ID",
+	}
+	if config.Broadcaster != nil {
+		routes = append(routes, "   📍 GET /api/stream[?kind=<KIND>] - Live events (Server-Sent Events)")
+	}
+	if watcherManager != nil {
+		routes = append(routes,
+			"   📍 POST /api/resync[?kind=<KIND>[&namespace=<NS>]] - Re-list and reconcile one or every resource type (admin)",
+			"   📍 POST /api/rollback?kind=<KIND>&name=<NAME>&namespace=<NS>&generation=<GEN> - Re-apply a stored generation (admin)")
+	}
+	routes = append(routes,
+		"   📍 GET /health - Health check",
+		"   📍 GET /metrics - Prometheus metrics",
+		"   📍 GET /api/health/details - Runtime details (throttled resources, pipeline backlog)")
+	logInfo(fmt.Sprintf("🌐 HTTP Server starting on :%s\n%s\n", port, strings.Join(routes, "\n")),
+		"HTTP server starting", slog.String("port", port), slog.Bool("admin_endpoints", watcherManager != nil))
 
 	return http.ListenAndServe(":"+port, nil)
 }
 
+// requireStorage wraps a Redis-backed handler so it answers 503 when running in degraded mode
+// (started without Redis, redisManager is nil)
+func requireStorage(redisManager *RedisManager, handler http.HandlerFunc) http.HandlerFunc {
+	if redisManager != nil {
+		return handler
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeErrorResponse(w, http.StatusServiceUnavailable, "Storage disabled: Redis was unavailable at startup (degraded mode)")
+	}
+}
+
+// diffErrorStatus is the HTTP status for a failed diff: 422 when it exceeded the DiffLimits
+func diffErrorStatus(err error) int {
+	if errors.Is(err, ErrDiffTooLarge) {
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusInternalServerError
+}
+
 // writeErrorResponse writes a formatted error response
 func writeErrorResponse(w http.ResponseWriter, statusCode int, message string) {
 	w.Header().Set("Content-Type", "application/json")
@@ -61,8 +235,52 @@ func writeErrorResponse(w http.ResponseWriter, statusCode int, message string) {
 	})
 }
 
-// getObjectGeneration extracts the generation number from a Kubernetes object
-func getObjectGeneration(obj interface{}) int64 {
+// writeNegotiatedResponse writes v as YAML when the request's Accept header asks for
+// application/yaml, and as JSON otherwise
+func writeNegotiatedResponse(w http.ResponseWriter, r *http.Request, v interface{}) {
+	if !acceptsYAML(r) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(v)
+		return
+	}
+
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to convert to YAML: %v", err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Write(data)
+}
+
+// acceptsYAML reports whether the Accept header lists a YAML media type
+func acceptsYAML(r *http.Request) bool {
+	for _, mediaRange := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType := strings.TrimSpace(strings.SplitN(mediaRange, ";", 2)[0])
+		switch mediaType {
+		case "application/yaml", "application/x-yaml", "text/yaml":
+			return true
+		}
+	}
+	return false
+}
+
+// nonNegativeIntParam parses an optional non-negative integer query parameter.
+// Returns false if the parameter is set but isn't a non-negative integer
+func nonNegativeIntParam(r *http.Request, name string, defaultValue int) (int, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return defaultValue, true
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		return 0, false
+	}
+	return parsed, true
+}
+
+// objectGeneration extracts the generation number from a Kubernetes object, see getObjectGeneration
+func objectGeneration(obj interface{}) int64 {
 	if obj == nil {
 		return 0
 	}
@@ -158,18 +376,44 @@ func getObjectTimestamp(obj interface{}) string {
 type ResourceHistoryItem struct {
 	Generation int64  `json:"generation"`
 	Timestamp  string `json:"timestamp"`
+	Summary    string `json:"summary,omitempty"`              // what changed from the previous stored version
+	Baseline   bool   `json:"baseline,omitempty"`             // scheduled snapshot rather than a change
+	Synthetic  bool   `json:"synthetic_generation,omitempty"` // generation is the stored version number; the object has none
+
+	// Raw watch events debounced into this version (1 = a single event) and the span they cover
+	CoalescedCount int    `json:"coalesced_count"`
+	CoalescedFrom  string `json:"coalesced_from,omitempty"`
+	CoalescedUntil string `json:"coalesced_until,omitempty"`
+}
+
+// defaultHistoryLimit is the page size of /api/history when no limit is given
+const defaultHistoryLimit = 50
+
+// ResourceHistoryPage is one page of a resource's history, newest generation first
+type ResourceHistoryPage struct {
+	Total  int                   `json:"total"`
+	Limit  int                   `json:"limit"`
+	Offset int                   `json:"offset"`
+	Items  []ResourceHistoryItem `json:"items"`
+}
+
+// ResourceList is the response for /api/resources
+type ResourceList struct {
+	Items     []ResourceTuple `json:"items"`
+	Truncated bool            `json:"truncated"` // the key scan hit the per-request budget, so items are partial
 }
 
 // ResourceTuple represents a kind/name/namespace tuple
 type ResourceTuple struct {
-	Kind      string `json:"kind"`
-	Name      string `json:"name"`
-	Namespace string `json:"namespace"`
+	Kind        string            `json:"kind"`
+	Name        string            `json:"name"`
+	Namespace   string            `json:"namespace"`
+	FocusFields map[string]string `json:"focus_fields,omitempty"` // latest summary fields, only set by /api/resources
 }
 
-// handleGetResourceHistory handles GET /api/history?kind=<KIND>&name=<NAME>&namespace=<NAMESPACE>
-// API 1: Returns list of changes (only generation & timestamp)
-func handleGetResourceHistory(w http.ResponseWriter, r *http.Request, redisManager *RedisManager) {
+// handleGetResourceHistory handles GET /api/history?kind=<KIND>&name=<NAME>&namespace=<NAMESPACE>&limit=<N>&offset=<N>
+// API 1: Returns a page of changes (only generation & timestamp), newest generation first
+func handleGetResourceHistory(w http.ResponseWriter, r *http.Request, redisManager *RedisManager, scopes resourceScopes) {
 	if r.Method != http.MethodGet {
 		writeErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
 		return
@@ -180,8 +424,24 @@ func handleGetResourceHistory(w http.ResponseWriter, r *http.Request, redisManag
 	name := r.URL.Query().Get("name")
 	namespace := r.URL.Query().Get("namespace")
 
-	if kind == "" || name == "" || namespace == "" {
-		writeErrorResponse(w, http.StatusBadRequest, "Missing required parameters: kind, name, namespace")
+	if scopes.missingParams(kind, name, namespace) {
+		writeErrorResponse(w, http.StatusBadRequest, "Missing required parameters: kind, name, namespace (except for cluster-scoped kinds)")
+		return
+	}
+
+	limit, ok := nonNegativeIntParam(r, "limit", defaultHistoryLimit)
+	if !ok {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid limit. Must be a non-negative integer.")
+		return
+	}
+	offset, ok := nonNegativeIntParam(r, "offset", 0)
+	if !ok {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid offset. Must be a non-negative integer.")
+		return
+	}
+	location, err := requestTimezone(r)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
@@ -199,25 +459,69 @@ func handleGetResourceHistory(w http.ResponseWriter, r *http.Request, redisManag
 		return
 	}
 
-	// Extract generation and timestamp from each object
-	history := make([]ResourceHistoryItem, 0, len(objects))
-	for _, obj := range objects {
+	// Newest generation first; stable so a baseline keeps its place next to its generation
+	order := make([]int, len(objects))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return getObjectGeneration(objects[order[a]]) > getObjectGeneration(objects[order[b]])
+	})
+
+	start := min(offset, len(order))
+	end := min(start+limit, len(order))
+
+	// Extract generation and timestamp from each object on the requested page
+	history := make([]ResourceHistoryItem, 0, end-start)
+	for _, i := range order[start:end] {
+		obj := objects[i]
 		generation := getObjectGeneration(obj)
 		timestamp := getObjectTimestamp(obj)
-		
+
+		// Objects are stored most recent first, so the previous version is the next one in storage
+		summary := ""
+		if i+1 < len(objects) {
+			summary = ResourceChange{Changes: BuildChangeMap(unwrapStoredObject(objects[i+1]), unwrapStoredObject(obj))}.Summary()
+		}
+
+		coalescedCount, coalescedFrom, coalescedUntil := coalescedSpan(obj)
 		history = append(history, ResourceHistoryItem{
-			Generation: generation,
-			Timestamp:  timestamp,
+			Generation:     generation,
+			Timestamp:      inTimezone(timestamp, location),
+			Summary:        summary,
+			Baseline:       isBaselineEntry(obj),
+			Synthetic:      isSyntheticGeneration(obj),
+			CoalescedCount: coalescedCount,
+			CoalescedFrom:  inTimezone(coalescedFrom, location),
+			CoalescedUntil: inTimezone(coalescedUntil, location),
 		})
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(history)
+	setHistoryHeaders(w, objects)
+	writeNegotiatedResponse(w, r, ResourceHistoryPage{
+		Total:  len(objects),
+		Limit:  limit,
+		Offset: offset,
+		Items:  history,
+	})
+}
+
+// setHistoryHeaders describes a resource's stored history in response headers so clients can
+// decide whether to refetch without parsing the body. objects are most recent first
+func setHistoryHeaders(w http.ResponseWriter, objects []interface{}) {
+	w.Header().Set("X-History-Count", strconv.Itoa(len(objects)))
+	if len(objects) == 0 {
+		return
+	}
+	w.Header().Set("X-Latest-Generation", strconv.FormatInt(getObjectGeneration(objects[0]), 10))
+	if latest, err := time.Parse(time.RFC3339, getObjectTimestamp(objects[0])); err == nil {
+		w.Header().Set("Last-Modified", latest.UTC().Format(http.TimeFormat))
+	}
 }
 
 // handleGetGenerationYAML handles GET /api/generation?kind=<KIND>&name=<NAME>&namespace=<NAMESPACE>&generation=<GEN>
 // API 2: Returns the YAML for only the specified generation
-func handleGetGenerationYAML(w http.ResponseWriter, r *http.Request, redisManager *RedisManager) {
+func handleGetGenerationYAML(w http.ResponseWriter, r *http.Request, redisManager *RedisManager, scopes resourceScopes) {
 	if r.Method != http.MethodGet {
 		writeErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
 		return
@@ -229,8 +533,8 @@ func handleGetGenerationYAML(w http.ResponseWriter, r *http.Request, redisManage
 	namespace := r.URL.Query().Get("namespace")
 	generationStr := r.URL.Query().Get("generation")
 
-	if kind == "" || name == "" || namespace == "" || generationStr == "" {
-		writeErrorResponse(w, http.StatusBadRequest, "Missing required parameters: kind, name, namespace, generation")
+	if scopes.missingParams(kind, name, namespace) || generationStr == "" {
+		writeErrorResponse(w, http.StatusBadRequest, "Missing required parameters: kind, name, namespace (except for cluster-scoped kinds), generation")
 		return
 	}
 
@@ -269,6 +573,13 @@ func handleGetGenerationYAML(w http.ResponseWriter, r *http.Request, redisManage
 		return
 	}
 
+	// A diff whose base version was lost (e.g. corrupted history) can't be rebuilt
+	if isDiffEntry(foundObject) {
+		writeErrorResponse(w, http.StatusInternalServerError,
+			fmt.Sprintf("Generation %d of %s is stored as a diff whose base version is missing", targetGeneration, resourceKey))
+		return
+	}
+
 	// Unwrap the StoredObject to get the actual Kubernetes object
 	actualObject := foundObject
 	if objMap, ok := foundObject.(map[string]interface{}); ok {
@@ -277,71 +588,843 @@ func handleGetGenerationYAML(w http.ResponseWriter, r *http.Request, redisManage
 		}
 	}
 
+	cleanOptions, err := parseCleanOptions(r)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	// Convert to YAML
-	yamlString, err := ConvertToYAMLWithStoredMetadata(actualObject)
+	yamlString, err := ConvertToYAMLWithStoredMetadataOptions(actualObject, cleanOptions)
 	if err != nil {
 		writeErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to convert to YAML: %v", err))
 		return
 	}
 
+	setHistoryHeaders(w, objects)
 	w.Header().Set("Content-Type", "application/yaml")
 	w.Write([]byte(yamlString))
 }
 
+// handleGetLatestYAML handles GET /api/latest?kind=<KIND>&name=<NAME>&namespace=<NAMESPACE>
+// API 9: Returns the YAML of the stored version with the highest generation (the most recent one
+// stored with it), sparing a lookup of the latest generation through /api/history
+func handleGetLatestYAML(w http.ResponseWriter, r *http.Request, redisManager *RedisManager, scopes resourceScopes) {
+	if r.Method != http.MethodGet {
+		writeErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	kind := r.URL.Query().Get("kind")
+	name := r.URL.Query().Get("name")
+	namespace := r.URL.Query().Get("namespace")
+
+	if scopes.missingParams(kind, name, namespace) {
+		writeErrorResponse(w, http.StatusBadRequest, "Missing required parameters: kind, name, namespace (except for cluster-scoped kinds)")
+		return
+	}
+
+	cleanOptions, err := parseCleanOptions(r)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resourceKey := fmt.Sprintf("%s/%s/%s", kind, name, namespace)
+	objects, err := redisManager.GetResourceObjects(resourceKey)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to retrieve resource: %v", err))
+		return
+	}
+	if len(objects) == 0 {
+		writeErrorResponse(w, http.StatusNotFound, fmt.Sprintf("Resource not found: %s", resourceKey))
+		return
+	}
+
+	// Versions are most recent first, so the first one with the highest generation wins ties
+	latest := objects[0]
+	for _, obj := range objects[1:] {
+		if getObjectGeneration(obj) > getObjectGeneration(latest) {
+			latest = obj
+		}
+	}
+
+	if isDiffEntry(latest) {
+		writeErrorResponse(w, http.StatusInternalServerError,
+			fmt.Sprintf("Generation %d of %s is stored as a diff whose base version is missing", getObjectGeneration(latest), resourceKey))
+		return
+	}
+
+	yamlString, err := ConvertToYAMLWithStoredMetadataOptions(unwrapStoredObject(latest), cleanOptions)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to convert to YAML: %v", err))
+		return
+	}
+
+	setHistoryHeaders(w, objects)
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Write([]byte(yamlString))
+}
+
+// parseCleanOptions reads YAML cleaning options from the query (?includeStatus=false, ?includeManagedFields=true),
+// defaulting to the full object without managedFields
+func parseCleanOptions(r *http.Request) (CleanOptions, error) {
+	opts := DefaultCleanOptions()
+	if includeStatus := r.URL.Query().Get("includeStatus"); includeStatus != "" {
+		keep, err := strconv.ParseBool(includeStatus)
+		if err != nil {
+			return opts, fmt.Errorf("Invalid includeStatus value. Must be true or false.")
+		}
+		opts.KeepStatus = keep
+	}
+	if includeManagedFields := r.URL.Query().Get("includeManagedFields"); includeManagedFields != "" {
+		keep, err := strconv.ParseBool(includeManagedFields)
+		if err != nil {
+			return opts, fmt.Errorf("Invalid includeManagedFields value. Must be true or false.")
+		}
+		opts.KeepManagedFields = keep
+	}
+	return opts, nil
+}
+
 // handleListAllResources handles GET /api/resources
 // API 3: Returns all Kind/Name/Namespace tuples by querying keys in Redis
-func handleListAllResources(w http.ResponseWriter, r *http.Request, redisManager *RedisManager) {
+func handleListAllResources(w http.ResponseWriter, r *http.Request, redisManager *RedisManager, scans *scanLimiter) {
 	if r.Method != http.MethodGet {
 		writeErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
 		return
 	}
 
-	// Get all resource keys
-	keys, err := redisManager.GetAllResourceKeys()
+	if !scans.acquire(r) {
+		return
+	}
+	defer scans.release()
+
+	// Scan resource keys, stopping at the per-request budget
+	keys, truncated, err := redisManager.ScanResourceKeys(scans.budget)
 	if err != nil {
 		writeErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to retrieve resource keys: %v", err))
 		return
 	}
 
+	// Redis returns keys in no particular order; sort them for stable output
+	sort.Strings(keys)
+
+	// One HMGET for every resource's one-line summary rather than fetching full objects
+	focusFields, err := redisManager.GetFocusFields(keys)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to retrieve focus fields: %v", err))
+		return
+	}
+
 	// Parse keys into tuples
 	resources := make([]ResourceTuple, 0, len(keys))
 	for _, key := range keys {
 		parts := strings.Split(key, "/")
 		if len(parts) == 3 {
 			resources = append(resources, ResourceTuple{
-				Kind:      parts[0],
-				Name:      parts[1],
-				Namespace: parts[2],
+				Kind:        parts[0],
+				Name:        parts[1],
+				Namespace:   parts[2],
+				FocusFields: focusFields[key],
 			})
 		}
 	}
 
+	if truncated {
+		w.Header().Set("X-Truncated", "true")
+	}
+	writeNegotiatedResponse(w, r, ResourceList{Items: resources, Truncated: truncated})
+}
+
+// CompareResult is the response for /api/compare
+type CompareResult struct {
+	ResourceA ResourceTuple `json:"resource_a"`
+	ResourceB ResourceTuple `json:"resource_b"`
+	Identical bool          `json:"identical"`
+	Changes   []FieldChange `json:"changes"`
+}
+
+// handleCompareResources handles GET /api/compare?kindA=&nameA=&namespaceA=&kindB=&nameB=&namespaceB=
+// API 4: Returns the field diff between the latest stored versions of two different resources
+func handleCompareResources(w http.ResponseWriter, r *http.Request, redisManager *RedisManager, scopes resourceScopes) {
+	if r.Method != http.MethodGet {
+		writeErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	query := r.URL.Query()
+	resourceA := ResourceTuple{Kind: query.Get("kindA"), Name: query.Get("nameA"), Namespace: query.Get("namespaceA")}
+	resourceB := ResourceTuple{Kind: query.Get("kindB"), Name: query.Get("nameB"), Namespace: query.Get("namespaceB")}
+
+	if scopes.missingParams(resourceA.Kind, resourceA.Name, resourceA.Namespace) ||
+		scopes.missingParams(resourceB.Kind, resourceB.Name, resourceB.Namespace) {
+		writeErrorResponse(w, http.StatusBadRequest, "Missing required parameters: kindA, nameA, namespaceA, kindB, nameB, namespaceB (namespaces except for cluster-scoped kinds)")
+		return
+	}
+
+	objectA, status, err := getLatestStoredObject(redisManager, resourceA)
+	if err != nil {
+		writeErrorResponse(w, status, err.Error())
+		return
+	}
+
+	objectB, status, err := getLatestStoredObject(redisManager, resourceB)
+	if err != nil {
+		writeErrorResponse(w, status, err.Error())
+		return
+	}
+
+	changes, err := GetFieldChanges(normalizeForComparison(objectA), normalizeForComparison(objectB))
+	if err != nil {
+		writeErrorResponse(w, diffErrorStatus(err), fmt.Sprintf("Failed to compare resources: %v", err))
+		return
+	}
+	if changes == nil {
+		changes = []FieldChange{}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(resources)
+	json.NewEncoder(w).Encode(CompareResult{
+		ResourceA: resourceA,
+		ResourceB: resourceB,
+		Identical: len(changes) == 0,
+		Changes:   changes,
+	})
 }
 
-// getObjectKind extracts the kind from a Kubernetes object
-func getObjectKind(obj interface{}) string {
-	if obj == nil {
-		return ""
+// getLatestStoredObject returns the most recent stored version of a resource, unwrapped from its StoredObject.
+// On failure it also returns the HTTP status code to respond with
+func getLatestStoredObject(redisManager *RedisManager, resource ResourceTuple) (interface{}, int, error) {
+	resourceKey := fmt.Sprintf("%s/%s/%s", resource.Kind, resource.Name, resource.Namespace)
+
+	objects, err := redisManager.GetResourceObjects(resourceKey)
+	if err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("Failed to retrieve resource: %v", err)
+	}
+	if len(objects) == 0 {
+		return nil, http.StatusNotFound, fmt.Errorf("Resource not found: %s", resourceKey)
 	}
 
-	// First, unwrap if it's a StoredObject
-	actualObj := obj
-	if objMap, ok := obj.(map[string]interface{}); ok {
+	// Most recent version is first (LPUSH)
+	latest := objects[0]
+	if objMap, ok := latest.(map[string]interface{}); ok {
 		if innerObj, hasObject := objMap["object"]; hasObject {
-			actualObj = innerObj
+			latest = innerObj
 		}
 	}
 
-	// Try to convert to map (for unstructured objects)
-	if objMap, ok := actualObj.(map[string]interface{}); ok {
-		if kind, hasKind := objMap["kind"]; hasKind {
-			if kindStr, ok := kind.(string); ok {
-				return kindStr
+	return latest, http.StatusOK, nil
+}
+
+// normalizeForComparison strips identity and bookkeeping fields so that only
+// meaningful differences between two distinct resources are reported
+func normalizeForComparison(obj interface{}) map[string]interface{} {
+	normalized := CleanKubernetesObject(obj)
+
+	if metadata, ok := normalized["metadata"].(map[string]interface{}); ok {
+		for _, field := range []string{"name", "namespace", "uid", "resourceVersion", "generation", "creationTimestamp", "selfLink"} {
+			delete(metadata, field)
+		}
+	}
+
+	// Status reflects runtime state, not configuration
+	delete(normalized, "status")
+
+	return normalized
+}
+
+// isBaselineEntry reports whether a stored history entry is a scheduled baseline snapshot
+func isBaselineEntry(obj interface{}) bool {
+	objMap, ok := obj.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	baseline, _ := objMap["baseline"].(bool)
+	return baseline
+}
+
+// unwrapStoredObject returns the Kubernetes object inside a StoredObject wrapper as a map
+func unwrapStoredObject(obj interface{}) map[string]interface{} {
+	objMap, ok := obj.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	if innerObj, ok := objMap["object"].(map[string]interface{}); ok {
+		return innerObj
+	}
+	return objMap
+}
+
+// DiffResponse is the JSON response for /api/diff
+type DiffResponse struct {
+	Resource       ResourceTuple `json:"resource"`
+	FromGeneration int64         `json:"from_generation"`
+	ToGeneration   int64         `json:"to_generation"`
+	Changes        []FieldChange `json:"changes"`
+}
+
+// handleDiff handles GET /api/diff?kind=<KIND>&name=<NAME>&namespace=<NAMESPACE>[&from=<GEN>&to=<GEN>&format=<FORMAT>]
+// API 5: Returns the field diff between two stored versions of a resource (default: previous vs latest).
+// format is json (default), ascii, markdown (GitHub-flavored, paste-ready for PRs and docs), or jsonpatch (RFC 6902)
+func handleDiff(w http.ResponseWriter, r *http.Request, redisManager *RedisManager, scopes resourceScopes) {
+	if r.Method != http.MethodGet {
+		writeErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	query := r.URL.Query()
+	kind := query.Get("kind")
+	name := query.Get("name")
+	namespace := query.Get("namespace")
+
+	if scopes.missingParams(kind, name, namespace) {
+		writeErrorResponse(w, http.StatusBadRequest, "Missing required parameters: kind, name, namespace (except for cluster-scoped kinds)")
+		return
+	}
+
+	format := query.Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "ascii" && format != "markdown" && format != "jsonpatch" {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid format. Must be json, ascii, markdown, or jsonpatch.")
+		return
+	}
+
+	resourceKey := fmt.Sprintf("%s/%s/%s", kind, name, namespace)
+
+	objects, err := redisManager.GetResourceObjects(resourceKey)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to retrieve resource: %v", err))
+		return
+	}
+
+	if len(objects) == 0 {
+		writeErrorResponse(w, http.StatusNotFound, fmt.Sprintf("Resource not found: %s", resourceKey))
+		return
+	}
+
+	// Objects are most recent first: default to the latest version and the one stored before it
+	toObject, err := findVersion(objects, query.Get("to"), 0)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	fromObject, err := findVersion(objects, query.Get("from"), 1)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if toObject == nil || fromObject == nil {
+		writeErrorResponse(w, http.StatusNotFound,
+			fmt.Sprintf("Requested versions not found for resource %s (%d stored)", resourceKey, len(objects)))
+		return
+	}
+
+	oldObject := CleanKubernetesObject(unwrapStoredObject(fromObject))
+	newObject := CleanKubernetesObject(unwrapStoredObject(toObject))
+
+	if format == "ascii" {
+		diffResult, err := DiffJSON(oldObject, newObject)
+		if err != nil {
+			writeErrorResponse(w, diffErrorStatus(err), fmt.Sprintf("Failed to diff versions: %v", err))
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte(diffResult.AsciiDiff))
+		return
+	}
+
+	if format == "jsonpatch" {
+		patch, err := DiffAsJSONPatch(oldObject, newObject)
+		if err != nil {
+			writeErrorResponse(w, diffErrorStatus(err), fmt.Sprintf("Failed to diff versions: %v", err))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json-patch+json")
+		w.Write(patch)
+		return
+	}
+
+	changes, err := GetFieldChanges(oldObject, newObject)
+	if err != nil {
+		writeErrorResponse(w, diffErrorStatus(err), fmt.Sprintf("Failed to diff versions: %v", err))
+		return
+	}
+	if changes == nil {
+		changes = []FieldChange{}
+	}
+
+	if format == "markdown" {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		fmt.Fprintf(w, "### %s `%s/%s`: generation %d → %d\n\n",
+			kind, namespace, name, getObjectGeneration(fromObject), getObjectGeneration(toObject))
+		w.Write([]byte(FormatChangesMarkdown(changes)))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(DiffResponse{
+		Resource:       ResourceTuple{Kind: kind, Name: name, Namespace: namespace},
+		FromGeneration: getObjectGeneration(fromObject),
+		ToGeneration:   getObjectGeneration(toObject),
+		Changes:        changes,
+	})
+}
+
+// findVersion selects a stored version by generation (most recent match), or by position in the
+// history when generationStr is empty. Returns nil if no such version is stored
+func findVersion(objects []interface{}, generationStr string, defaultIndex int) (interface{}, error) {
+	if generationStr == "" {
+		if defaultIndex >= len(objects) {
+			return nil, nil
+		}
+		return objects[defaultIndex], nil
+	}
+
+	generation, err := strconv.ParseInt(generationStr, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid generation number %q. Must be a positive integer.", generationStr)
+	}
+	for _, obj := range objects {
+		if getObjectGeneration(obj) == generation {
+			return obj, nil
+		}
+	}
+	return nil, nil
+}
+
+// AuthorStats counts the stored changes attributed to one field manager
+type AuthorStats struct {
+	Manager string         `json:"manager"`
+	Total   int            `json:"total"`
+	ByKind  map[string]int `json:"by_kind"`
+}
+
+// AuthorsSummary is the response for /api/authors
+type AuthorsSummary struct {
+	Window  string        `json:"window"`
+	Since   string        `json:"since"`
+	Authors []AuthorStats `json:"authors"`
+
+	Truncated bool `json:"truncated"` // the key scan hit the per-request budget, so counts are partial
+}
+
+// handleAuthors handles GET /api/authors?window=<DURATION>&kind=<KIND>
+// API 6: Returns stored change counts per field manager (humans vs automation) within the window
+// (default 24h), most active first. Baseline snapshots are not changes and aren't counted
+func handleAuthors(w http.ResponseWriter, r *http.Request, redisManager *RedisManager, scans *scanLimiter) {
+	if r.Method != http.MethodGet {
+		writeErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	window := 24 * time.Hour
+	if windowStr := r.URL.Query().Get("window"); windowStr != "" {
+		parsed, err := time.ParseDuration(windowStr)
+		if err != nil || parsed <= 0 {
+			writeErrorResponse(w, http.StatusBadRequest, "Invalid window. Must be a positive duration such as 1h or 168h.")
+			return
+		}
+		window = parsed
+	}
+	kindFilter := r.URL.Query().Get("kind")
+	since := time.Now().UTC().Add(-window)
+
+	if !scans.acquire(r) {
+		return
+	}
+	defer scans.release()
+
+	keys, truncated, err := redisManager.ScanResourceKeys(scans.budget)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to retrieve resource keys: %v", err))
+		return
+	}
+
+	stats := make(map[string]*AuthorStats)
+	for _, key := range keys {
+		kind := strings.SplitN(key, "/", 2)[0]
+		if kindFilter != "" && kind != kindFilter {
+			continue
+		}
+
+		objects, err := redisManager.GetResourceObjects(key)
+		if err != nil {
+			continue
+		}
+
+		// Most recent first: stop at the first entry older than the window
+		for _, obj := range objects {
+			storedAt, err := time.Parse(time.RFC3339, getObjectTimestamp(obj))
+			if err != nil || storedAt.Before(since) {
+				break
+			}
+			if isBaselineEntry(obj) {
+				continue
 			}
+
+			manager := storedChangeAuthor(obj)
+			author, ok := stats[manager]
+			if !ok {
+				author = &AuthorStats{Manager: manager, ByKind: make(map[string]int)}
+				stats[manager] = author
+			}
+			author.Total++
+			author.ByKind[kind]++
 		}
 	}
 
-	return ""
+	authors := make([]AuthorStats, 0, len(stats))
+	for _, author := range stats {
+		authors = append(authors, *author)
+	}
+	sort.Slice(authors, func(i, j int) bool {
+		if authors[i].Total != authors[j].Total {
+			return authors[i].Total > authors[j].Total
+		}
+		return authors[i].Manager < authors[j].Manager
+	})
+
+	if truncated {
+		w.Header().Set("X-Truncated", "true")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(AuthorsSummary{
+		Window:  window.String(),
+		Since:   since.Format(time.RFC3339),
+		Authors: authors,
+
+		Truncated: truncated,
+	})
+}
+
+// storedChangeAuthor returns the field manager recorded with a stored version, falling back to
+// the object's managedFields for versions stored before authorship was recorded
+func storedChangeAuthor(obj interface{}) string {
+	if objMap, ok := obj.(map[string]interface{}); ok {
+		if changedBy, ok := objMap["changed_by"].(string); ok && changedBy != "" {
+			return changedBy
+		}
+	}
+	return changeAuthor(unwrapStoredObject(obj))
+}
+
+// CorrelationResponse is the response for /api/by-correlation
+type CorrelationResponse struct {
+	ID      string             `json:"id"`
+	Count   int                `json:"count"`
+	Changes []CorrelatedChange `json:"changes"`
+}
+
+// handleByCorrelation handles GET /api/by-correlation?id=<ID>
+// API 7: Returns every stored change carrying the same correlation ID, oldest first
+func handleByCorrelation(w http.ResponseWriter, r *http.Request, redisManager *RedisManager) {
+	if r.Method != http.MethodGet {
+		writeErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		writeErrorResponse(w, http.StatusBadRequest, "Missing required parameter: id")
+		return
+	}
+	if redisManager.correlationAnnotation == "" {
+		writeErrorResponse(w, http.StatusNotFound, "Correlation is disabled. Start with --correlation-annotation to enable it.")
+		return
+	}
+
+	changes, err := redisManager.GetCorrelatedChanges(id)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to retrieve changes: %v", err))
+		return
+	}
+
+	w.Header().Set("X-History-Count", strconv.Itoa(len(changes)))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(CorrelationResponse{
+		ID:      id,
+		Count:   len(changes),
+		Changes: changes,
+	})
+}
+
+// streamKeepAlive is how often an idle /api/stream connection gets a comment line, so proxies
+// don't close it
+const streamKeepAlive = 15 * time.Second
+
+// handleExport handles GET /api/export?kind=<KIND>&name=<NAME>&namespace=<NAMESPACE>[&format=zip|tar.gz]
+// API 8: Streams every stored version of a resource as gen-<n>.yaml files in a zip (default) or tar.gz archive
+func handleExport(w http.ResponseWriter, r *http.Request, redisManager *RedisManager, scopes resourceScopes) {
+	if r.Method != http.MethodGet {
+		writeErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	kind := r.URL.Query().Get("kind")
+	name := r.URL.Query().Get("name")
+	namespace := r.URL.Query().Get("namespace")
+	if scopes.missingParams(kind, name, namespace) {
+		writeErrorResponse(w, http.StatusBadRequest, "Missing required parameters: kind, name, namespace (except for cluster-scoped kinds)")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	var contentType string
+	switch format {
+	case "", ExportFormatZip:
+		format = ExportFormatZip
+		contentType = "application/zip"
+	case ExportFormatTarGz:
+		contentType = "application/gzip"
+	default:
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid format. Must be zip or tar.gz.")
+		return
+	}
+
+	cleanOptions, err := parseCleanOptions(r)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resourceKey := fmt.Sprintf("%s/%s/%s", kind, name, namespace)
+	objects, err := redisManager.GetResourceObjects(resourceKey)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to retrieve resource: %v", err))
+		return
+	}
+	if len(objects) == 0 {
+		writeErrorResponse(w, http.StatusNotFound, fmt.Sprintf("Resource not found: %s", resourceKey))
+		return
+	}
+
+	// Convert everything before writing so a conversion error can still be reported as JSON
+	entries, err := historyExportEntries(objects, cleanOptions)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to export resource: %v", err))
+		return
+	}
+
+	filename := fmt.Sprintf("%s-%s-%s.%s", kind, namespace, name, format)
+	setHistoryHeaders(w, objects)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	if err := writeExportArchive(w, format, entries); err != nil {
+		// Headers are already sent; all we can do is log and cut the archive short
+		logWarn(fmt.Sprintf("⚠️  Failed to write export of %s: %v", resourceKey, err),
+			"failed to write export", append(resourceAttrs(kind, namespace, name),
+				slog.String("format", format), slog.Int64("generation", getObjectGeneration(objects[0])), slog.String("error", err.Error()))...)
+	}
+}
+
+// handleStream handles GET /api/stream?kind=<KIND>
+// Holds the connection open and pushes each processed event as a Server-Sent Events data frame,
+// optionally only events of one kind. The client is unsubscribed when the request ends
+func handleStream(w http.ResponseWriter, r *http.Request, broadcaster *EventBroadcaster) {
+	if r.Method != http.MethodGet {
+		writeErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeErrorResponse(w, http.StatusInternalServerError, "Streaming is not supported")
+		return
+	}
+
+	events, unsubscribe := broadcaster.Subscribe(r.URL.Query().Get("kind"))
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case data := <-events:
+			fmt.Fprintf(w, "data: %s\n\n", data)
+			flusher.Flush()
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// handleResync handles POST /api/resync[?kind=<KIND>&namespace=<NAMESPACE>]
+// Admin: Re-lists a resource type (or all of them) and reconciles the pipeline's state and Redis,
+// returning how many resources were reconciled per kind
+func handleResync(w http.ResponseWriter, r *http.Request, watcherManager *WatcherManager) {
+	if r.Method != http.MethodPost {
+		writeErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	kind := r.URL.Query().Get("kind")
+	namespace := r.URL.Query().Get("namespace")
+
+	// Without a kind, every watched kind is resynced
+	if kind == "" {
+		if namespace != "" {
+			writeErrorResponse(w, http.StatusBadRequest, "namespace requires kind")
+			return
+		}
+		perKind, err := watcherManager.ResyncAll(r.Context())
+		if errors.Is(err, ErrResyncInProgress) {
+			writeErrorResponse(w, http.StatusConflict, "Resync failed: a resync is already running")
+			return
+		}
+		if err != nil {
+			writeErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Resync failed: %v", err))
+			return
+		}
+
+		reconciled := 0
+		for _, count := range perKind {
+			reconciled += count
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(HTTPResponse{
+			Success: true,
+			Message: fmt.Sprintf("Resynced %d kinds", len(perKind)),
+			Data:    map[string]interface{}{"reconciled": reconciled, "kinds": perKind},
+		})
+		return
+	}
+
+	if !watcherManager.IsWatched(kind) {
+		writeErrorResponse(w, http.StatusNotFound, fmt.Sprintf("Resource kind not watched: %s", kind))
+		return
+	}
+
+	reconciled, err := watcherManager.Resync(r.Context(), kind, namespace)
+	if errors.Is(err, ErrResyncInProgress) {
+		writeErrorResponse(w, http.StatusConflict, "Resync failed: a resync is already running")
+		return
+	}
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Resync failed: %v", err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(HTTPResponse{
+		Success: true,
+		Message: fmt.Sprintf("Resynced %s", kind),
+		Data:    map[string]interface{}{"reconciled": reconciled, "kinds": map[string]int{kind: reconciled}},
+	})
+}
+
+// handleRollback handles POST /api/rollback
+// Admin: Restores a resource by applying one of its stored generations back to the cluster
+func handleRollback(w http.ResponseWriter, r *http.Request, redisManager *RedisManager, watcherManager *WatcherManager, scopes resourceScopes) {
+	if r.Method != http.MethodPost {
+		writeErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	kind := r.URL.Query().Get("kind")
+	name := r.URL.Query().Get("name")
+	namespace := r.URL.Query().Get("namespace")
+	generationStr := r.URL.Query().Get("generation")
+
+	if scopes.missingParams(kind, name, namespace) || generationStr == "" {
+		writeErrorResponse(w, http.StatusBadRequest, "Missing required parameters: kind, name, namespace (except for cluster-scoped kinds), generation")
+		return
+	}
+
+	targetGeneration, err := strconv.ParseInt(generationStr, 10, 64)
+	if err != nil || targetGeneration <= 0 {
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid generation number. Must be a positive integer.")
+		return
+	}
+
+	if !watcherManager.IsWatched(kind) {
+		writeErrorResponse(w, http.StatusNotFound, fmt.Sprintf("Resource kind not watched: %s", kind))
+		return
+	}
+
+	resourceKey := fmt.Sprintf("%s/%s/%s", kind, name, namespace)
+	objects, err := redisManager.GetResourceObjects(resourceKey)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Failed to retrieve resource: %v", err))
+		return
+	}
+
+	// The most recent version stored with the generation wins, as in /api/generation
+	var foundObject interface{}
+	for _, obj := range objects {
+		if getObjectGeneration(obj) == targetGeneration {
+			foundObject = obj
+			break
+		}
+	}
+	if foundObject == nil {
+		writeErrorResponse(w, http.StatusNotFound,
+			fmt.Sprintf("Generation %d not found for resource %s", targetGeneration, resourceKey))
+		return
+	}
+	if isDiffEntry(foundObject) {
+		writeErrorResponse(w, http.StatusInternalServerError,
+			fmt.Sprintf("Generation %d of %s is stored as a diff whose base version is missing", targetGeneration, resourceKey))
+		return
+	}
+
+	obj, err := rollbackObject(foundObject)
+	if err != nil {
+		writeErrorResponse(w, http.StatusConflict, fmt.Sprintf("Cannot roll back %s: %v", resourceKey, err))
+		return
+	}
+
+	applied, err := watcherManager.Rollback(r.Context(), obj)
+	if err != nil {
+		writeErrorResponse(w, http.StatusInternalServerError, fmt.Sprintf("Rollback failed: %v", err))
+		return
+	}
+
+	logInfo(fmt.Sprintf("⏪ Rolled back %s to generation %d", resourceKey, targetGeneration),
+		"rolled back resource", append(resourceAttrs(kind, namespace, name), slog.Int64("generation", targetGeneration))...)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(HTTPResponse{
+		Success: true,
+		Message: fmt.Sprintf("Rolled back %s to generation %d", resourceKey, targetGeneration),
+		Data:    applied.Object,
+	})
+}
+
+// HealthDetails is the response for /api/health/details
+type HealthDetails struct {
+	PipelineQueueLength int                 `json:"pipeline_queue_length"`
+	ThrottledResources  []ThrottledResource `json:"throttled_resources"`
+}
+
+// handleHealthDetails handles GET /api/health/details
+// Returns runtime details such as the resources currently throttled by adaptive sampling
+func handleHealthDetails(w http.ResponseWriter, r *http.Request, pipeline *EventPipeline) {
+	if r.Method != http.MethodGet {
+		writeErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	details := HealthDetails{ThrottledResources: []ThrottledResource{}}
+	if pipeline != nil {
+		details.PipelineQueueLength = pipeline.QueueLength()
+		details.ThrottledResources = pipeline.ThrottledResources()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(HTTPResponse{
+		Success: true,
+		Data:    details,
+	})
 }
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRollbackRejectsNonPositiveGenerations(t *testing.T) {
+	for _, generation := range []string{"0", "-3", "abc"} {
+		req := httptest.NewRequest(http.MethodPost, "/api/rollback?kind=Deployment&name=web&namespace=default&generation="+generation, nil)
+		rec := httptest.NewRecorder()
+		// Validation must fail before the managers are touched
+		handleRollback(rec, req, nil, nil, resourceScopes{})
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("generation %s: got status %d, want %d", generation, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// compareServiceAccounts highlights security-relevant ServiceAccount changes: secret and
+// imagePullSecret references and automountServiceAccountToken.
+// Newly referenced secrets and enabling token automount are raised as alerts
+func compareServiceAccounts(old, new *unstructured.Unstructured) *ComparatorResult {
+	result := newComparatorResult()
+
+	for _, field := range []string{"secrets", "imagePullSecrets"} {
+		oldRefs, _, _ := unstructured.NestedSlice(old.Object, field)
+		newRefs, _, _ := unstructured.NestedSlice(new.Object, field)
+
+		oldByName, oldOrder := listItemsByName(oldRefs)
+		newByName, newOrder := listItemsByName(newRefs)
+
+		for _, name := range oldOrder {
+			if _, exists := newByName[name]; !exists {
+				result.addChange(fmt.Sprintf("%s[%s]", field, name), name, nil)
+			}
+		}
+		for _, name := range newOrder {
+			if _, exists := oldByName[name]; !exists {
+				result.addChange(fmt.Sprintf("%s[%s]", field, name), nil, name)
+				result.Alerts = append(result.Alerts,
+					fmt.Sprintf("ServiceAccount %s/%s: now references secret %q in %s",
+						new.GetNamespace(), new.GetName(), name, field))
+			}
+		}
+	}
+
+	oldAutomount, oldSet, _ := unstructured.NestedBool(old.Object, "automountServiceAccountToken")
+	newAutomount, newSet, _ := unstructured.NestedBool(new.Object, "automountServiceAccountToken")
+	if oldSet != newSet || oldAutomount != newAutomount {
+		result.addChange("automountServiceAccountToken", automountSetting(oldAutomount, oldSet), automountSetting(newAutomount, newSet))
+		if oldSet && !oldAutomount && (!newSet || newAutomount) {
+			result.Alerts = append(result.Alerts,
+				fmt.Sprintf("ServiceAccount %s/%s: token automount re-enabled", new.GetNamespace(), new.GetName()))
+		}
+	}
+
+	return result
+}
+
+// automountSetting describes automountServiceAccountToken, which defaults to enabled when unset
+func automountSetting(value bool, set bool) interface{} {
+	if !set {
+		return "unset (default true)"
+	}
+	return value
+}
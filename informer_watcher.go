@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+// WatchResourceWithInformer is WatchResource built on a client-go shared informer per namespace
+// (or one across all namespaces when namespaces is empty). client-go handles re-listing and
+// reconnecting, and every resync period the cached objects are replayed as MODIFIED refresh
+// events, which bypass resourceVersion deduplication so the pipeline re-checks them. Objects reach the pipeline as the same ResourceEvents. Blocks until
+// ctx is cancelled or opts.Timeout elapses
+func WatchResourceWithInformer(
+	ctx context.Context,
+	dynamicClient dynamic.Interface,
+	gvr schema.GroupVersionResource,
+	namespaces []string,
+	kind string,
+	listOptions metav1.ListOptions,
+	pipeline *EventPipeline,
+	opts WatchOptions,
+) error {
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	if len(namespaces) == 0 {
+		informScope(ctx, dynamicClient, gvr, metav1.NamespaceAll, "all namespaces", kind, listOptions, pipeline, opts)
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	for _, namespace := range namespaces {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			informScope(ctx, dynamicClient, gvr, namespace, "namespace "+namespace, kind, listOptions, pipeline, opts)
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// informScope runs one informer until ctx is cancelled, sending its events to the pipeline
+func informScope(
+	ctx context.Context,
+	dynamicClient dynamic.Interface,
+	gvr schema.GroupVersionResource,
+	namespace string,
+	scope string,
+	kind string,
+	listOptions metav1.ListOptions,
+	pipeline *EventPipeline,
+	opts WatchOptions,
+) {
+	if !opts.StartLimiter.Acquire(ctx) {
+		return
+	}
+
+	factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(dynamicClient, opts.InformerResync, namespace,
+		func(options *metav1.ListOptions) {
+			options.LabelSelector = listOptions.LabelSelector
+			options.FieldSelector = listOptions.FieldSelector
+		})
+	informer := factory.ForResource(gvr).Informer()
+
+	send := func(eventType watch.EventType, obj interface{}, refresh bool) {
+		opts.Activity.Record(kind, scope)
+		cached, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			reportCastFailure(&UnexpectedObjectError{
+				Kind:     kind,
+				Scope:    scope,
+				Context:  fmt.Sprintf("informer %s", eventType),
+				Expected: "*unstructured.Unstructured",
+				Object:   obj,
+			})
+			return
+		}
+		// redactObject copies before replacing anything, leaving the informer's cache untouched
+		object := redactObject(cached)
+		pipeline.SendEvent(ResourceEvent{
+			Type:          EventTypeFromWatch(eventType),
+			RawType:       eventType,
+			ResourceKind:  kind,
+			Namespace:     object.GetNamespace(),
+			Name:          object.GetName(),
+			Object:        object,
+			Timestamp:     time.Now(),
+			ManagedFields: object.GetManagedFields(),
+			Refresh:       refresh,
+		})
+	}
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) { send(watch.Added, obj, false) },
+		UpdateFunc: func(old, obj interface{}) {
+			// A resync replays the cached object unchanged, with the resourceVersion already processed
+			send(watch.Modified, obj, isResync(old, obj))
+		},
+		DeleteFunc: func(obj interface{}) {
+			// The final state of an object deleted while disconnected is only known from the cache
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			send(watch.Deleted, obj, false)
+		},
+	})
+	if err != nil {
+		opts.StartLimiter.Abandon()
+		logError(fmt.Sprintf("❌ Failed to start informer for %s in %s: %v", kind, scope, err),
+			"failed to start informer", append(watchAttrs(kind, scope), slog.String("error", err.Error()))...)
+		return
+	}
+
+	// The informer's initial List counts towards the List phase like the watchers' own
+	opts.ListLimiter.Acquire()
+	factory.Start(ctx.Done())
+	synced := cache.WaitForCacheSync(ctx.Done(), informer.HasSynced)
+	opts.ListLimiter.Release(kind, scope, len(informer.GetStore().ListKeys()))
+	if !synced {
+		opts.StartLimiter.Abandon()
+		factory.Shutdown()
+		logInfo(fmt.Sprintf("🛑 Stopped watching %s in %s", kind, scope), "stopped watching", watchAttrs(kind, scope)...)
+		return
+	}
+
+	logInfo(fmt.Sprintf("✅ Watching %s in %s for changes (informer)", kind, scope), "watching", watchAttrs(kind, scope)...)
+	opts.StartLimiter.Online(kind, scope)
+
+	<-ctx.Done()
+	factory.Shutdown()
+	logInfo(fmt.Sprintf("🛑 Stopped watching %s in %s", kind, scope), "stopped watching", watchAttrs(kind, scope)...)
+}
+
+// isResync reports whether an informer update only replays the cached object: the old and new
+// objects have the same resourceVersion
+func isResync(old, new interface{}) bool {
+	oldObj, oldOk := old.(*unstructured.Unstructured)
+	newObj, newOk := new.(*unstructured.Unstructured)
+	return oldOk && newOk && oldObj.GetResourceVersion() == newObj.GetResourceVersion()
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/yudai/gojsondiff"
+)
+
+// JSONPatchOperation is one RFC 6902 JSON Patch operation
+type JSONPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// DiffAsJSONPatch returns an RFC 6902 JSON Patch document (add, remove and replace operations
+// with JSON Pointer paths) turning old into new. Operations apply in order; array elements are
+// removed from the highest index down before new ones are added
+func DiffAsJSONPatch(old, new interface{}) ([]byte, error) {
+	oldJSON, err := json.Marshal(old)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal old object: %w", err)
+	}
+	newJSON, err := json.Marshal(new)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal new object: %w", err)
+	}
+	if err := checkDiffDepth(oldJSON, newJSON); err != nil {
+		return nil, err
+	}
+
+	diff, err := gojsondiff.New().Compare(oldJSON, newJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare JSON: %w", err)
+	}
+
+	ops := make([]JSONPatchOperation, 0)
+	if diff.Modified() {
+		deltas := diff.Deltas()
+		if err := checkDiffDeltas(deltas); err != nil {
+			return nil, err
+		}
+		var newDoc interface{}
+		json.Unmarshal(newJSON, &newDoc)
+		if ops, err = appendPatchOperations(ops, deltas, "", newDoc); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(ops)
+}
+
+// appendPatchOperations appends the operations for the deltas of the object or array at pointer,
+// whose new value is newValue
+func appendPatchOperations(ops []JSONPatchOperation, deltas []gojsondiff.Delta, pointer string, newValue interface{}) ([]JSONPatchOperation, error) {
+	ordered, moved := orderPatchDeltas(deltas)
+	if moved {
+		// Reordered array elements: replacing the whole array keeps the patch simple and correct
+		return appendPatchOperation(ops, "replace", pointer, newValue)
+	}
+
+	var err error
+	for _, delta := range ordered {
+		switch d := delta.(type) {
+		case *gojsondiff.Object:
+			path := pointer + "/" + patchToken(d.PostPosition())
+			ops, err = appendPatchOperations(ops, d.Deltas, path, patchChild(newValue, d.PostPosition()))
+		case *gojsondiff.Array:
+			path := pointer + "/" + patchToken(d.PostPosition())
+			ops, err = appendPatchOperations(ops, d.Deltas, path, patchChild(newValue, d.PostPosition()))
+		case *gojsondiff.Added:
+			ops, err = appendPatchOperation(ops, "add", pointer+"/"+patchToken(d.PostPosition()), d.Value)
+		case *gojsondiff.Deleted:
+			ops = append(ops, JSONPatchOperation{Op: "remove", Path: pointer + "/" + patchToken(d.PrePosition())})
+		case *gojsondiff.Modified:
+			ops, err = appendPatchOperation(ops, "replace", pointer+"/"+patchToken(d.PostPosition()), d.NewValue)
+		case *gojsondiff.TextDiff:
+			ops, err = appendPatchOperation(ops, "replace", pointer+"/"+patchToken(d.PostPosition()), d.NewValue)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ops, nil
+}
+
+// appendPatchOperation appends an operation carrying a value
+func appendPatchOperation(ops []JSONPatchOperation, op, path string, value interface{}) ([]JSONPatchOperation, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal value of %s: %w", path, err)
+	}
+	return append(ops, JSONPatchOperation{Op: op, Path: path, Value: data}), nil
+}
+
+// orderPatchDeltas orders deltas so their operations apply in sequence. Array deltas use old
+// indexes for removals and new indexes for the rest, so removals come first (highest index
+// first), then additions and changes by ascending index. Reports whether an element moved
+func orderPatchDeltas(deltas []gojsondiff.Delta) ([]gojsondiff.Delta, bool) {
+	removed := make([]gojsondiff.Delta, 0)
+	rest := make([]gojsondiff.Delta, 0, len(deltas))
+	for _, delta := range sortedDeltas(deltas) {
+		switch delta.(type) {
+		case *gojsondiff.Moved:
+			return nil, true
+		case *gojsondiff.Deleted:
+			removed = append(removed, delta)
+		default:
+			rest = append(rest, delta)
+		}
+	}
+
+	sort.SliceStable(removed, func(i, j int) bool {
+		indexI, okI := removed[i].(gojsondiff.PreDelta).PrePosition().(gojsondiff.Index)
+		indexJ, okJ := removed[j].(gojsondiff.PreDelta).PrePosition().(gojsondiff.Index)
+		return okI && okJ && indexI > indexJ
+	})
+	return append(removed, rest...), false
+}
+
+// patchToken returns a diff position as a JSON Pointer reference token, escaping ~ and /
+func patchToken(position gojsondiff.Position) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(position.String())
+}
+
+// patchChild returns the member or element of value at position, or nil if there is none
+func patchChild(value interface{}, position gojsondiff.Position) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return v[position.String()]
+	case []interface{}:
+		if index, ok := position.(gojsondiff.Index); ok && int(index) >= 0 && int(index) < len(v) {
+			return v[index]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+)
+
+// keyedSpecLists are spec lists whose items are matched by name instead of by position when
+// diffing, so inserting an item doesn't report every item after it as changed
+var keyedSpecLists = map[string][]string{
+	"Gateway":   {"listeners"},
+	"HTTPRoute": {"rules"},
+	"GRPCRoute": {"rules"},
+	"TCPRoute":  {"rules"},
+}
+
+// diffSpec returns the field changes between two specs of a kind. Keyed lists whose items all have
+// a unique name are diffed by name, with paths like "listeners[https].port"; everything else
+// (including keyed lists with unnamed items, such as route rules without names) by position
+func diffSpec(kind string, oldSpec, newSpec map[string]interface{}) ([]FieldChange, error) {
+	oldRest := make(map[string]interface{}, len(oldSpec))
+	for field, value := range oldSpec {
+		oldRest[field] = value
+	}
+	newRest := make(map[string]interface{}, len(newSpec))
+	for field, value := range newSpec {
+		newRest[field] = value
+	}
+
+	keyed := make([]FieldChange, 0)
+	for _, field := range keyedSpecLists[kind] {
+		oldList, _ := oldSpec[field].([]interface{})
+		newList, _ := newSpec[field].([]interface{})
+		changes, ok, err := diffKeyedList(field, oldList, newList)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		keyed = append(keyed, changes...)
+		delete(oldRest, field)
+		delete(newRest, field)
+	}
+
+	changes, err := GetFieldChanges(oldRest, newRest)
+	if err != nil {
+		return nil, err
+	}
+	return append(changes, keyed...), nil
+}
+
+// diffKeyedList diffs two lists item by item, matching items by name. Returns false when an
+// item has no name or shares it with another, so the list can't be keyed
+func diffKeyedList(field string, oldList, newList []interface{}) ([]FieldChange, bool, error) {
+	oldByName, oldOrder := listItemsByName(oldList)
+	newByName, newOrder := listItemsByName(newList)
+	if !uniquelyNamed(oldList, oldByName) || !uniquelyNamed(newList, newByName) {
+		return nil, false, nil
+	}
+
+	changes := make([]FieldChange, 0)
+	for _, name := range newOrder {
+		path := field + "[" + name + "]"
+		oldItem, existed := oldByName[name]
+		if !existed {
+			changes = append(changes, FieldChange{Type: "ADDED", Path: path, NewValue: newByName[name]})
+			continue
+		}
+		if reflect.DeepEqual(oldItem, newByName[name]) {
+			continue
+		}
+		itemChanges, err := GetFieldChanges(oldItem, newByName[name])
+		if err != nil {
+			return nil, false, err
+		}
+		for _, change := range itemChanges {
+			if strings.HasPrefix(change.Path, "[") {
+				change.Path = path + change.Path
+			} else {
+				change.Path = path + "." + change.Path
+			}
+			changes = append(changes, change)
+		}
+	}
+	for _, name := range oldOrder {
+		if _, exists := newByName[name]; !exists {
+			changes = append(changes, FieldChange{Type: "REMOVED", Path: field + "[" + name + "]", OldValue: oldByName[name]})
+		}
+	}
+	return changes, true, nil
+}
+
+// uniquelyNamed reports whether every item of list is an object with its own non-empty name
+func uniquelyNamed(list []interface{}, byName map[string]map[string]interface{}) bool {
+	_, unnamed := byName[""]
+	return !unnamed && len(byName) == len(list)
+}
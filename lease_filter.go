@@ -0,0 +1,49 @@
+package main
+
+import (
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ChangeFilter decides whether a modification of a resource is worth recording.
+// It runs before storage; returning false drops the event
+type ChangeFilter func(old, new *unstructured.Unstructured) bool
+
+// defaultChangeFilters returns the built-in change filters keyed by resource kind
+func defaultChangeFilters() map[string]ChangeFilter {
+	return map[string]ChangeFilter{
+		"Lease": leaseHolderChanged,
+	}
+}
+
+// leaseHolderChanged only lets through actual leadership transitions of a coordination.k8s.io Lease.
+// Leader-election renewals rewrite spec.renewTime every few seconds and are not worth auditing
+func leaseHolderChanged(old, new *unstructured.Unstructured) bool {
+	if new.GroupVersionKind().Group != "coordination.k8s.io" {
+		return true // some other kind named Lease
+	}
+
+	oldHolder, _, _ := unstructured.NestedString(old.Object, "spec", "holderIdentity")
+	newHolder, _, _ := unstructured.NestedString(new.Object, "spec", "holderIdentity")
+	if oldHolder != newHolder {
+		return true
+	}
+
+	// Labels/annotations edits are still real changes
+	return !reflect.DeepEqual(old.GetLabels(), new.GetLabels()) ||
+		!reflect.DeepEqual(old.GetAnnotations(), new.GetAnnotations())
+}
+
+// compareLeases reports leadership transitions
+func compareLeases(old, new *unstructured.Unstructured) *ComparatorResult {
+	result := newComparatorResult()
+
+	oldHolder, _, _ := unstructured.NestedString(old.Object, "spec", "holderIdentity")
+	newHolder, _, _ := unstructured.NestedString(new.Object, "spec", "holderIdentity")
+	if oldHolder != newHolder {
+		result.addChange("spec.holderIdentity", oldHolder, newHolder)
+	}
+
+	return result
+}
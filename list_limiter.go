@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// ListLimiter bounds how many initial List calls run against the API server at once
+// and reports List-phase progress as each resource/namespace finishes listing
+type ListLimiter struct {
+	slots     chan struct{}
+	total     int
+	completed int
+	mutex     sync.Mutex
+}
+
+// NewListLimiter creates a limiter allowing up to concurrency parallel List calls.
+// total is the number of List calls expected (used for progress reporting only)
+func NewListLimiter(concurrency int, total int) *ListLimiter {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &ListLimiter{
+		slots: make(chan struct{}, concurrency),
+		total: total,
+	}
+}
+
+// Acquire blocks until a List slot is free
+func (ll *ListLimiter) Acquire() {
+	if ll == nil {
+		return
+	}
+	ll.slots <- struct{}{}
+}
+
+// Release frees a List slot and records progress for the finished List
+func (ll *ListLimiter) Release(kind string, scope string, count int) {
+	if ll == nil {
+		return
+	}
+	<-ll.slots
+	ll.complete(fmt.Sprintf("%s in %s: %d items", kind, scope, count))
+}
+
+// Skip records progress for a List that didn't need to run (e.g. a watch resumed from a checkpoint)
+func (ll *ListLimiter) Skip(kind string, scope string) {
+	if ll == nil {
+		return
+	}
+	ll.complete(fmt.Sprintf("%s in %s: resumed, no List needed", kind, scope))
+}
+
+// complete records one finished List and reports progress
+func (ll *ListLimiter) complete(detail string) {
+	ll.mutex.Lock()
+	ll.completed++
+	completed := ll.completed
+	ll.mutex.Unlock()
+
+	logInfo(fmt.Sprintf("📋 List phase progress: %d/%d complete (%s)", completed, ll.total, detail), "list phase progress",
+		slog.Int("completed", completed), slog.Int("total", ll.total), slog.String("detail", detail))
+
+	if completed == ll.total {
+		logInfo("✅ List phase complete - all watchers are now live", "list phase complete")
+	}
+}
+
+// CountListCalls returns how many initial List calls the given resources will make
+func CountListCalls(resources []ResourceConfig) int {
+	total := 0
+	for _, resource := range resources {
+		if len(resource.Namespaces) == 0 {
+			total++
+		} else {
+			total += len(resource.Namespaces)
+		}
+	}
+	return total
+}
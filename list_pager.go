@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// defaultListPageSize is how many objects a paginated List requests per page
+const defaultListPageSize = 500
+
+// listPages lists a resource page by page (pageSize items per page, following continue tokens)
+// and calls onPage as each page arrives, so callers never hold the whole collection in memory.
+// A pageSize <= 0 lists everything in one call. Each page goes through the circuit breaker.
+// Returns the list's resourceVersion and the total number of items
+func listPages(
+	ctx context.Context,
+	client resourceClient,
+	listOptions metav1.ListOptions,
+	pageSize int64,
+	breaker *CircuitBreaker,
+	onPage func(items []unstructured.Unstructured),
+) (string, int, error) {
+	if pageSize > 0 {
+		listOptions.Limit = pageSize
+	}
+	listOptions.Continue = ""
+
+	total := 0
+	for {
+		var page *unstructured.UnstructuredList
+		err := breaker.Do(ctx, func() error {
+			var listErr error
+			page, listErr = client.List(ctx, listOptions)
+			return listErr
+		})
+		if err != nil {
+			return "", total, err
+		}
+
+		onPage(page.Items)
+		total += len(page.Items)
+
+		// Every page is served from the same snapshot, so the last page's resourceVersion is the list's
+		if page.GetContinue() == "" {
+			return page.GetResourceVersion(), total, nil
+		}
+		listOptions.Continue = page.GetContinue()
+	}
+}
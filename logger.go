@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+// LogFormat selects how log lines are written
+type LogFormat string
+
+const (
+	// LogFormatText writes the human-readable lines meant for a terminal (the default)
+	LogFormatText LogFormat = "text"
+	// LogFormatJSON writes one JSON object per line with level, message and structured fields
+	LogFormatJSON LogFormat = "json"
+)
+
+// jsonLogger receives log lines in JSON mode (nil in text mode)
+var jsonLogger *slog.Logger
+
+// SetLogFormat selects the log format. In JSON mode lines go to os.Stdout as it is at the time
+// of the call, so call it after redirecting stdout (e.g. for --output ndjson)
+func SetLogFormat(format LogFormat) error {
+	switch format {
+	case LogFormatText:
+		jsonLogger = nil
+	case LogFormatJSON:
+		jsonLogger = slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	default:
+		return fmt.Errorf("unknown log format %q (expected %s or %s)", format, LogFormatText, LogFormatJSON)
+	}
+	return nil
+}
+
+// structuredLogging reports whether logs are JSON. Multi-line debug output such as full object
+// dumps is only written in text mode
+func structuredLogging() bool {
+	return jsonLogger != nil
+}
+
+// logAt writes one log line: text as-is in text mode, or msg with attrs in JSON mode
+func logAt(level slog.Level, text string, msg string, attrs ...slog.Attr) {
+	if jsonLogger == nil {
+		fmt.Println(text)
+		return
+	}
+	jsonLogger.LogAttrs(context.Background(), level, msg, attrs...)
+}
+
+// logInfo writes an informational log line, see logAt
+func logInfo(text string, msg string, attrs ...slog.Attr) {
+	logAt(slog.LevelInfo, text, msg, attrs...)
+}
+
+// logWarn writes a warning log line, see logAt
+func logWarn(text string, msg string, attrs ...slog.Attr) {
+	logAt(slog.LevelWarn, text, msg, attrs...)
+}
+
+// logError writes an error log line, see logAt
+func logError(text string, msg string, attrs ...slog.Attr) {
+	logAt(slog.LevelError, text, msg, attrs...)
+}
+
+// resourceAttrs are the structured fields identifying a resource
+func resourceAttrs(kind, namespace, name string) []slog.Attr {
+	return []slog.Attr{
+		slog.String("kind", kind),
+		slog.String("namespace", namespace),
+		slog.String("name", name),
+	}
+}
+
+// watchAttrs are the structured fields identifying a watch
+func watchAttrs(kind, scope string) []slog.Attr {
+	return []slog.Attr{
+		slog.String("kind", kind),
+		slog.String("scope", scope),
+	}
+}
@@ -1,27 +1,131 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"log/slog"
 	"os"
-	"path/filepath"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
+// buildKubeConfig returns the Kubernetes client config. Unless an explicit kubeconfig path is given,
+// it first tries the in-cluster ServiceAccount config (running as a pod) and falls back to
+// $KUBECONFIG or ~/.kube/config
+func buildKubeConfig(kubeConfigPath string) (*rest.Config, error) {
+	if kubeConfigPath == "" {
+		if config, err := rest.InClusterConfig(); err == nil {
+			logInfo("🏠 Using in-cluster Kubernetes config", "using in-cluster Kubernetes config")
+			return config, nil
+		}
+	}
+
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		kubeConfigLoadingRules(kubeConfigPath),
+		&clientcmd.ConfigOverrides{},
+	).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	return config, nil
+}
+
 func main() {
 	// Command-line flags
 	configFile := flag.String("config", "resources.json", "Path to resources configuration file")
+	kubeConfigPath := flag.String("kubeconfig", "", "Path to kubeconfig (overrides in-cluster config and $KUBECONFIG)")
 	redisAddr := flag.String("redis", "localhost:6379", "Redis server address")
+	redisPassword := flag.String("redis-password", "", "Redis AUTH password (defaults to $REDIS_PASSWORD)")
+	redisDB := flag.Int("redis-db", 0, "Redis database index")
+	redisTLS := flag.Bool("redis-tls", false, "Connect to Redis over TLS")
+	redisDialTimeout := flag.Duration("redis-dial-timeout", 5*time.Second, "Timeout for establishing a Redis connection")
+	redisConnectAttempts := flag.Int("redis-connect-attempts", 5, "Initial Redis connection attempts, with exponential backoff, before giving up")
+	redisMaxRetries := flag.Int("redis-max-retries", 3, "Retries of a Redis command after a transient error")
+	redisRequired := flag.Bool("redis-required", true, "Exit if Redis is unavailable at startup; when false, run in degraded mode with storage disabled")
 	maxChanges := flag.Int("max-changes", 100, "Maximum number of changes to keep in queue")
+	historyTTL := flag.Duration("history-ttl", 0, "Expire a resource's stored history this long after its last change, e.g. 168h for short-lived resources (0 = keep forever)")
 	httpPort := flag.String("port", "8080", "HTTP server port")
+	listConcurrency := flag.Int("list-concurrency", 4, "Maximum number of initial List calls running in parallel")
+	maxNamespaceWatches := flag.Int("max-concurrent-namespace-watches", 16, "Maximum number of (resource, namespace) watches being started at once; the rest wait their turn (0 = unbounded)")
+	listPageSize := flag.Int64("list-page-size", defaultListPageSize, "Objects per page of the initial List; events are sent as each page arrives (0 = unpaginated)")
+	namespace := flag.String("namespace", "", "Default namespace when the config doesn't specify one (defaults to the kubeconfig context namespace)")
+	breakerThreshold := flag.Int("breaker-failures", 5, "Consecutive Kubernetes API failures before the circuit breaker opens")
+	breakerCooldown := flag.Duration("breaker-cooldown", 30*time.Second, "How long the circuit breaker stays open before a half-open probe")
+	restoreState := flag.Bool("restore-state", false, "Seed the pipeline's previous states from the latest Redis snapshots on startup")
+	eventTypes := flag.String("event-types", "ADDED,MODIFIED,DELETED", "Comma-separated event types to record (ADDED, MODIFIED, DELETED)")
+	noManagedFields := flag.String("no-managed-fields", "compare", "Handling of events without managedFields: compare (deep-equal against previous state), process, or skip")
+	adminEndpoints := flag.Bool("admin-endpoints", false, "Expose admin HTTP endpoints such as POST /api/resync and POST /api/rollback")
+	resumeWatches := flag.Bool("resume-watches", false, "Checkpoint watch resourceVersions to Redis and resume from them on restart (pairs well with --restore-state)")
+	enableCompaction := flag.Bool("enable-compaction", false, "Periodically remove history entries identical to their predecessor")
+	compactionInterval := flag.Duration("compaction-interval", time.Hour, "How often history compaction runs")
+	scanConcurrency := flag.Int("scan-concurrency", 4, "Maximum concurrent HTTP requests scanning Redis keys")
+	scanBudget := flag.Int("scan-budget", 10000, "Maximum keys scanned per HTTP request before returning partial results (0 = unlimited)")
+	throttleThreshold := flag.Int("throttle-threshold", 0, "Changes per --throttle-window after which a resource is throttled (0 disables adaptive sampling)")
+	throttleWindow := flag.Duration("throttle-window", time.Minute, "Sliding window used to measure per-resource change rate")
+	debounceWindow := flag.Duration("debounce-window", 0, "Coalesce events for the same resource arriving within this window, keeping only the last (e.g. 1s; 0 disables)")
+	dedupeVersions := flag.Bool("dedupe-resource-versions", true, "Drop events whose resourceVersion isn't newer than the last one processed for the resource (e.g. replayed after a watch reconnect)")
+	throttleSample := flag.Int("throttle-sample", 10, "While throttled, store 1 in N changes (0 suppresses all changes)")
+	leaseSuppression := flag.Bool("lease-suppression", true, "Only record leadership transitions for coordination.k8s.io Leases, not renewals")
+	otelEndpoint := flag.String("otel-endpoint", "", "OTLP/HTTP collector endpoint for pipeline traces (e.g. localhost:4318). Empty disables tracing")
+	equalityMode := flag.String("equality", string(EqualityIgnoreManagedFieldsTime), "How modifications are compared to the previous state to drop no-op re-applies: ignore-managed-fields-time, ignore-managed-fields, or strict (never drop)")
+	canonicalKinds := flag.String("canonicalize", "Gateway,Service", "Comma-separated kinds whose server-injected defaults (Gateway listener allowedRoutes, Service port protocol/targetPort) are stripped before diffing (empty disables)")
+	snapshotInterval := flag.Duration("snapshot-interval", 0, "Store a baseline snapshot of all watched resources at every multiple of this interval, e.g. 24h for midnight UTC (0 disables)")
+	watchTimeout := flag.Duration("watch-timeout", 0, "Stop each resource's watchers after this long, e.g. for bounded test runs (0 = watch until shutdown)")
+	correlationAnnotation := flag.String("correlation-annotation", "", "Annotation whose value groups stored changes for GET /api/by-correlation, e.g. deployment.kubernetes.io/revision (empty disables)")
+	rbacCheck := flag.Bool("rbac-check", true, "Check list/watch permission for each configured resource at startup and skip the forbidden ones")
+	outputMode := flag.String("output", OutputText, "Output mode: text (human-readable logs on stdout) or ndjson (every stored change as one JSON line on stdout, logs on stderr)")
+	debugDumpFile := flag.String("debug-dump-file", "", "File the SIGUSR1 debug dump of in-memory state is written to (empty = stderr)")
+	timezone := flag.String("timezone", "UTC", "IANA timezone (e.g. Europe/Berlin) timestamps are shown in by the human-readable output; stored timestamps stay UTC")
+	logFormat := flag.String("log-format", string(LogFormatText), "Log format: text (human-readable) or json (one structured object per line, for log pipelines)")
+	diffMaxDepth := flag.Int("diff-max-depth", 100, "Abandon a diff when either object nests deeper than this, storing the change with a diff-skipped marker (0 = unlimited)")
+	diffMaxDeltas := flag.Int("diff-max-deltas", 10000, "Abandon a diff with more changed fields than this, storing the change with a diff-skipped marker (0 = unlimited)")
+	useInformers := flag.Bool("use-informers", false, "Watch through client-go shared informers (client-go handles re-listing and reconnects) instead of raw watches; not used for metadataOnly resources")
+	informerResync := flag.Duration("informer-resync", 10*time.Minute, "With --use-informers, how often cached objects are replayed through the pipeline, which drops those unchanged since last processed (0 = never)")
+	diffIgnorePaths := flag.String("diff-ignore-paths", strings.Join(DefaultDiffIgnorePaths, ","), "Comma-separated dotted field paths left out of logged diffs and the field_changes of stored changes (empty = none)")
+	redactionKey := flag.String("redaction-key", "", "Secret keying the digests that replace redacted values (defaults to $REDACTION_KEY, else a random key per process)")
+	redactFields := flag.String("redact-fields", "", "Comma-separated dotted field paths redacted in every kind (e.g. spec.tls.privateKey); Secret data, stringData and last-applied-configuration are always redacted")
+	awaitCRDs := flag.Bool("await-crds", true, "Start watching resources whose CRDs aren't installed at startup once they are, instead of skipping them")
+	dryRun := flag.Bool("dry-run", false, "Run the pipeline and log changes without writing anything to Redis (stored history is still readable)")
+	drainTimeout := flag.Duration("drain-timeout", 5*time.Second, "How long to wait for in-flight pipeline events on shutdown")
 	flag.Parse()
 
-	home, _ := os.UserHomeDir()
-	kubeConfigPath := filepath.Join(home, ".kube", "config")
+	// In NDJSON mode stdout carries only change records; everything printed for humans goes to stderr
+	var changeOutput *NDJSONWriter
+	switch *outputMode {
+	case OutputText:
+	case OutputNDJSON:
+		changeOutput = NewNDJSONWriter(os.Stdout)
+		os.Stdout = os.Stderr
+	default:
+		logError(fmt.Sprintf("❌ Invalid --output %q (expected %s or %s)", *outputMode, OutputText, OutputNDJSON),
+			"invalid --output", slog.String("output", *outputMode))
+		os.Exit(1)
+	}
+	if err := SetLogFormat(LogFormat(*logFormat)); err != nil {
+		logError(fmt.Sprintf("❌ Invalid --log-format: %v", err), "invalid --log-format", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	if err := SetDisplayTimezone(*timezone); err != nil {
+		logError(fmt.Sprintf("❌ Invalid --timezone: %v", err), "invalid --timezone", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	// Cancelled on SIGINT/SIGTERM; stops all watchers
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	config, err := clientcmd.BuildConfigFromFlags("", kubeConfigPath)
+	config, err := buildKubeConfig(*kubeConfigPath)
 	if err != nil {
 		panic(err)
 	}
@@ -32,45 +136,183 @@ func main() {
 		panic(err)
 	}
 
-	fmt.Println("🚀 Starting Generic Kubernetes Watcher")
-	fmt.Println("=======================================")
+	// Metadata client - used for resources configured with metadataOnly
+	metadataClient, err := metadata.NewForConfig(config)
+	if err != nil {
+		panic(err)
+	}
+
+	// Discovery client - used to skip resources whose CRDs aren't installed
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		panic(err)
+	}
+
+	logInfo("🚀 Starting Generic Kubernetes Watcher\n=======================================", "starting watcher")
 
 	// ========================================================================
 	// STEP 0: Initialize Redis Manager
 	// ========================================================================
-	fmt.Printf("🔗 Connecting to Redis at %s...\n", *redisAddr)
-	redisManager, err := NewRedisManager(*redisAddr, "annotation_changes", *maxChanges)
-	if err != nil {
-		fmt.Printf("❌ Failed to connect to Redis: %v\n", err)
-		panic(err)
+	logInfo(fmt.Sprintf("🔗 Connecting to Redis at %s...", *redisAddr), "connecting to Redis", slog.String("addr", *redisAddr))
+	if *redisPassword == "" {
+		*redisPassword = os.Getenv("REDIS_PASSWORD")
+	}
+	redisConfig := RedisConfig{
+		Addr:            *redisAddr,
+		Password:        *redisPassword,
+		DB:              *redisDB,
+		TLS:             *redisTLS,
+		DialTimeout:     *redisDialTimeout,
+		ConnectAttempts: *redisConnectAttempts,
+		MaxRetries:      *redisMaxRetries,
+	}
+	redisManager, err := NewRedisManager(redisConfig, "annotation_changes", *maxChanges, *historyTTL)
+	switch {
+	case errors.Is(err, ErrRedisUnavailable) && !*redisRequired:
+		// Degraded mode: watchers still run and log, but nothing is stored
+		logWarn(fmt.Sprintf("=======================================\n"+
+			"⚠️  DEGRADED MODE: %v\n"+
+			"⚠️  Storage is DISABLED - changes are logged but not recorded,\n"+
+			"⚠️  history APIs return 503 and /health reports degraded\n"+
+			"=======================================", err),
+			"degraded mode, storage disabled", slog.String("error", err.Error()))
+		redisManager = nil
+	case err != nil:
+		logError(fmt.Sprintf("❌ Failed to connect to Redis: %v (use --redis-required=false to run without storage)", err),
+			"failed to connect to Redis", slog.String("error", err.Error()))
+		os.Exit(1)
+	default:
+		logInfo("✅ Redis connected successfully", "Redis connected")
+		defer redisManager.Close()
+		if *dryRun {
+			redisManager.SetDryRun(true)
+			logInfo("🧪 DRY RUN: changes are logged but nothing is written to Redis", "dry run, Redis writes disabled")
+		}
+		if *correlationAnnotation != "" {
+			redisManager.SetCorrelationAnnotation(*correlationAnnotation)
+			logInfo(fmt.Sprintf("🔗 Grouping changes by annotation %s", *correlationAnnotation),
+				"grouping changes by annotation", slog.String("annotation", *correlationAnnotation))
+		}
 	}
-	fmt.Println("✅ Redis connected successfully")
-	defer redisManager.Close()
 
 	// ========================================================================
 	// STEP 1: Load configuration from JSON file
 	// ========================================================================
-	fmt.Printf("📄 Loading configuration from: %s\n", *configFile)
+	logInfo(fmt.Sprintf("📄 Loading configuration from: %s", *configFile), "loading configuration", slog.String("file", *configFile))
 
 	watcherConfig, err := LoadConfigFromFile(*configFile)
 	if err != nil {
-		fmt.Printf("⚠️  Failed to load config file: %v\n", err)
-		fmt.Println("📋 Using default configuration...")
-		watcherConfig = GetDefaultWatcherConfig()
+		logWarn(fmt.Sprintf("⚠️  Failed to load config file: %v", err), "failed to load config file", slog.String("error", err.Error()))
+		defaultNamespace := ResolveDefaultNamespace(*kubeConfigPath, *namespace)
+		logInfo(fmt.Sprintf("📋 Using default configuration in namespace %s...", defaultNamespace),
+			"using default configuration", slog.String("namespace", defaultNamespace))
+		watcherConfig = GetDefaultWatcherConfig(defaultNamespace)
 	} else {
-		fmt.Println("✅ Configuration loaded successfully")
+		logInfo("✅ Configuration loaded successfully", "configuration loaded")
+	}
+
+	if redisManager != nil {
+		focusFields, err := NewFocusFieldSet(watcherConfig.Resources)
+		if err != nil {
+			logError(fmt.Sprintf("❌ Invalid focusFields in configuration: %v", err), "invalid focusFields in configuration", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		redisManager.SetFocusFields(focusFields)
+		redisManager.SetFullObjectPolicy(watcherConfig.FullObjectPolicy())
 	}
 
 	// ========================================================================
 	// STEP 2: Create the Event Pipeline
 	// ========================================================================
 	pipeline := NewEventPipeline(1000, redisManager)
+
+	tracerProvider, err := NewTracerProvider(ctx, *otelEndpoint, "k8s-crud-watcher")
+	if err != nil {
+		logError(fmt.Sprintf("❌ Invalid --otel-endpoint: %v", err), "invalid --otel-endpoint", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	if tracerProvider != nil {
+		logInfo(fmt.Sprintf("🔭 Exporting pipeline traces to %s", *otelEndpoint), "exporting pipeline traces", slog.String("endpoint", *otelEndpoint))
+		pipeline.SetTracer(tracerProvider.Tracer(pipelineTracerName))
+		defer func() {
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			tracerProvider.Shutdown(shutdownCtx)
+		}()
+	}
 	// ========================================================================
 
+	selectedEventTypes, err := ParseEventTypes(*eventTypes)
+	if err != nil {
+		logError(fmt.Sprintf("❌ Invalid --event-types: %v", err), "invalid --event-types", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	pipeline.SetEventTypeFilter(selectedEventTypes)
+
+	if err := pipeline.SetNoManagedFieldsMode(NoManagedFieldsMode(*noManagedFields)); err != nil {
+		logError(fmt.Sprintf("❌ Invalid --no-managed-fields: %v", err), "invalid --no-managed-fields", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	equality, err := EqualityFuncForMode(EqualityMode(*equalityMode))
+	if err != nil {
+		logError(fmt.Sprintf("❌ Invalid --equality: %v", err), "invalid --equality", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	pipeline.SetEqualityFunc(equality)
+
+	canonical, err := ParseCanonicalKinds(*canonicalKinds)
+	if err != nil {
+		logError(fmt.Sprintf("❌ Invalid --canonicalize: %v", err), "invalid --canonicalize", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	pipeline.SetCanonicalKinds(canonical)
+
+	// Workloads configured with followOwned also watch the ReplicaSets, Jobs and Pods they own
+	owners, err := NewOwnerTracker(watcherConfig.GetEnabledResources())
+	if err != nil {
+		logError(fmt.Sprintf("❌ Invalid resource configuration: %v", err), "invalid resource configuration", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	pipeline.SetOwnerTracker(owners)
+	pipeline.SetStatusKinds(append(watcherConfig.StatusKinds(), owners.StatusKinds()...))
+	pipeline.SetChangeOutput(changeOutput)
+	pipeline.SetDebounceWindow(*debounceWindow)
+	pipeline.SetResourceVersionDedup(*dedupeVersions)
+	SetDiffLimits(DiffLimits{MaxDepth: *diffMaxDepth, MaxDeltas: *diffMaxDeltas})
+	if *redactionKey == "" {
+		*redactionKey = os.Getenv("REDACTION_KEY")
+	}
+	if *redactionKey == "" {
+		logWarn("⚠️  No --redaction-key set: redacted values will all read as changed once after a restart",
+			"no redaction key set, using a random key per process")
+	}
+	SetRedactionKey(*redactionKey)
+	SetRedactedFields(watcherConfig.RedactedFields(ParseFieldPaths(*redactFields)))
+	SetDiffIgnorePaths(ParseFieldPaths(*diffIgnorePaths))
+
+	if !*leaseSuppression {
+		pipeline.SetChangeFilter("Lease", nil)
+	}
+
+	pipeline.SetAdaptiveSampler(NewAdaptiveSampler(*throttleThreshold, *throttleWindow, *throttleSample))
+
+	if *restoreState {
+		restored, err := pipeline.RestoreStatesFromRedis()
+		if err != nil {
+			logWarn(fmt.Sprintf("⚠️  Failed to restore previous states from Redis: %v", err),
+				"failed to restore previous states", slog.String("error", err.Error()))
+		} else {
+			logInfo(fmt.Sprintf("♻️  Restored previous state for %d resources from Redis", restored),
+				"restored previous states", slog.Int("resources", restored))
+		}
+	}
+
 	// Handler 1: Alert on Gateway changes
 	pipeline.RegisterHandler(func(event ResourceEvent, changes *ChangeDetails) {
 		if event.ResourceKind == "Gateway" && event.Type == EventTypeModified {
-			fmt.Printf("🚨 ALERT: Gateway %s/%s was modified!\n", event.Namespace, event.Name)
+			logWarn(fmt.Sprintf("🚨 ALERT: Gateway %s/%s was modified!", event.Namespace, event.Name),
+				"gateway modified", resourceAttrs(event.ResourceKind, event.Namespace, event.Name)...)
 		}
 	})
 
@@ -78,17 +320,49 @@ func main() {
 	pipeline.RegisterHandler(func(event ResourceEvent, changes *ChangeDetails) {
 		if event.ResourceKind == "SecurityPolicy" {
 			if len(changes.SpecChanges) > 0 {
-				fmt.Printf("🔒 SECURITY: SecurityPolicy %s/%s spec changed!\n",
-					event.Namespace, event.Name)
+				text := fmt.Sprintf("🔒 SECURITY: SecurityPolicy %s/%s spec changed!", event.Namespace, event.Name)
+				attrs := resourceAttrs(event.ResourceKind, event.Namespace, event.Name)
+				if policy, ok := event.Object.(*unstructured.Unstructured); ok {
+					if kind, name, found := SecurityPolicyTargetRef(policy); found {
+						text += fmt.Sprintf("\n   Target: %s/%s", kind, name)
+						attrs = append(attrs, slog.String("target", kind+"/"+name))
+					}
+				}
+				logWarn(text, "security policy spec changed", attrs...)
 			}
 		}
 	})
 
-	// Handler 3: Log all changes
+	// Handler 3: Surface comparator alerts (e.g. webhook failurePolicy Fail → Ignore), workload image changes and route rule changes
+	pipeline.RegisterHandler(func(event ResourceEvent, changes *ChangeDetails) {
+		for _, alert := range changes.Alerts {
+			logWarn(fmt.Sprintf("🚨 POLICY ALERT: %s", alert), "policy alert",
+				append(resourceAttrs(event.ResourceKind, event.Namespace, event.Name), slog.String("alert", alert))...)
+		}
+		for _, imageChange := range changes.ImageChanges {
+			logInfo(fmt.Sprintf("🖼️  IMAGE CHANGE: %s %s/%s %s", event.ResourceKind, event.Namespace, event.Name, imageChange), "image change",
+				append(resourceAttrs(event.ResourceKind, event.Namespace, event.Name), slog.String("change", imageChange.String()))...)
+		}
+		for _, routeChange := range changes.RouteChanges {
+			logInfo(fmt.Sprintf("🔀 ROUTE CHANGE: %s %s/%s %s", event.ResourceKind, event.Namespace, event.Name, routeChange), "route change",
+				append(resourceAttrs(event.ResourceKind, event.Namespace, event.Name), slog.String("change", routeChange))...)
+		}
+	})
+
+	// Handler 4: Fan processed events out to /api/stream clients
+	broadcaster := NewEventBroadcaster()
+	pipeline.RegisterHandler(broadcaster.Handle)
+
+	// Handler 5: Log all changes
 	pipeline.RegisterHandler(func(event ResourceEvent, changes *ChangeDetails) {
 		if event.Type == EventTypeModified {
-			fmt.Printf("📊 CHANGE DETECTED: %s %s/%s\n",
-				event.ResourceKind, event.Namespace, event.Name)
+			paths := sortedKeys(changes.SpecChanges)
+			text := fmt.Sprintf("📊 CHANGE DETECTED: %s %s/%s", event.ResourceKind, event.Namespace, event.Name)
+			for _, path := range paths {
+				text += fmt.Sprintf("\n   ✏️  %s", path)
+			}
+			logInfo(text, "change detected",
+				append(resourceAttrs(event.ResourceKind, event.Namespace, event.Name), slog.Any("spec_paths", paths))...)
 		}
 	})
 
@@ -100,47 +374,144 @@ func main() {
 	// ========================================================================
 	// STEP 5: Start watchers for enabled resources
 	// ========================================================================
-	fmt.Println("\n📡 Starting Watchers...")
-	fmt.Println("   Enabled Resources:")
+	logInfo("\n📡 Starting Watchers...\n   Enabled Resources:", "starting watchers")
 
 	enabledResources := watcherConfig.GetEnabledResources()
 
 	if len(enabledResources) == 0 {
-		fmt.Println("   ⚠️  No resources enabled in configuration!")
+		logError("   ⚠️  No resources enabled in configuration!", "no resources enabled in configuration")
 		os.Exit(1)
 	}
+	enabledResources = append(enabledResources, owners.Resources()...)
 
+	// Skip resources whose CRDs aren't installed, or start them once they are with --await-crds
+	servedResources := make([]ResourceConfig, 0, len(enabledResources))
+	var awaitedResources []ResourceConfig
 	for _, resource := range enabledResources {
+		served, err := IsResourceServed(discoveryClient, resource.ToGVR())
+		if err != nil {
+			logWarn(fmt.Sprintf("      ⚠️  %s: %v (watching anyway)", resource.Kind, err),
+				"failed to check whether resource is served, watching anyway", slog.String("kind", resource.Kind), slog.String("error", err.Error()))
+		} else if !served && *awaitCRDs {
+			logWarn(fmt.Sprintf("      ⏳ %s (%s/%s/%s) - Not served by the API server (CRD not installed?), watching once it is",
+				resource.Kind, resource.Group, resource.Version, resource.Resource),
+				"resource not served, watching once it is", slog.String("kind", resource.Kind))
+			awaitedResources = append(awaitedResources, resource)
+			continue
+		} else if !served {
+			logWarn(fmt.Sprintf("      ✗ %s (%s/%s/%s) - Not served by the API server (CRD not installed?), skipping",
+				resource.Kind, resource.Group, resource.Version, resource.Resource),
+				"resource not served, skipping", slog.String("kind", resource.Kind))
+			continue
+		}
+		servedResources = append(servedResources, resource)
+	}
+
+	// Skip resources we aren't allowed to watch, instead of retrying Forbidden errors forever
+	if *rbacCheck {
+		var disabled []string
+		servedResources, disabled = FilterWatchableResources(ctx, dynamicClient, servedResources)
+		if len(disabled) > 0 {
+			logWarn(fmt.Sprintf("   🔐 %d resource(s) disabled by RBAC: %v", len(disabled), disabled),
+				"resources disabled by RBAC", slog.Any("resources", disabled))
+		}
+	}
+
+	// Bound the initial List phase so a large config doesn't overwhelm the API server, and
+	// share one circuit breaker so an API server outage backs off all watchers together
+	watchOptions := WatchOptions{
+		ListLimiter: NewListLimiter(*listConcurrency, CountListCalls(servedResources)),
+		Breaker:     NewCircuitBreaker(*breakerThreshold, *breakerCooldown),
+		Timeout:     *watchTimeout,
+		PageSize:    *listPageSize,
+		Activity:    NewWatchActivity(),
+
+		MetadataClient: metadataClient,
+
+		UseInformers:   *useInformers,
+		InformerResync: *informerResync,
+	}
+	StartDebugDumpOnSignal(ctx, *debugDumpFile, pipeline, watchOptions.Activity)
+	if *maxNamespaceWatches > 0 {
+		watchOptions.StartLimiter = NewWatchStartLimiter(*maxNamespaceWatches, CountListCalls(servedResources))
+	}
+	if *resumeWatches && redisManager != nil {
+		watchOptions.Checkpoints = NewResourceVersionStore(redisManager, 5*time.Second)
+		defer watchOptions.Checkpoints.Stop()
+	}
+	logInfo(fmt.Sprintf("   List concurrency: %d", *listConcurrency), "list concurrency", slog.Int("concurrency", *listConcurrency))
+
+	watcherManager := NewWatcherManager(dynamicClient, pipeline, watchOptions)
+
+	for _, resource := range servedResources {
 		namespaceStr := "all namespaces"
 		if len(resource.Namespaces) > 0 {
 			namespaceStr = fmt.Sprintf("%v", resource.Namespaces)
 		}
 
-		fmt.Printf("      ✓ %s (%s/%s) - Watching %s\n",
-			resource.Kind,
-			resource.Group,
-			resource.Resource,
-			namespaceStr)
+		if resource.LabelSelector != "" {
+			namespaceStr += fmt.Sprintf(" matching %q", resource.LabelSelector)
+		}
+		if resource.FieldSelector != "" {
+			namespaceStr += fmt.Sprintf(" with fields %q", resource.FieldSelector)
+		}
+		if resource.MetadataOnly {
+			namespaceStr += " (metadata only)"
+		}
+		if owners != nil && owners.followed[resource.Kind] {
+			namespaceStr += " (owned by followOwned workloads)"
+		}
+
+		logInfo(fmt.Sprintf("      ✓ %s (%s/%s) - Watching %s", resource.Kind, resource.Group, resource.Resource, namespaceStr),
+			"watching resource", slog.String("kind", resource.Kind), slog.String("scope", namespaceStr))
 
 		// Start watcher for this resource with its namespaces
-		go WatchResource(
-			dynamicClient,
-			resource.ToGVR(),
-			resource.Namespaces, // Pass namespace array
-			resource.Kind,
-			pipeline,
-		)
+		watcherManager.Start(ctx, resource)
+	}
+	for _, resource := range awaitedResources {
+		watcherManager.StartWhenServed(ctx, discoveryClient, resource)
+	}
+
+	logInfo("\n✅ All watchers active\n⚡ Pipeline running. Press Ctrl+C to stop\n=======================================\n",
+		"all watchers active")
+
+	if *enableCompaction && redisManager != nil {
+		go NewCompactor(redisManager, *compactionInterval).Start()
 	}
 
-	fmt.Println("\n✅ All watchers active")
-	fmt.Println("⚡ Pipeline running. Press Ctrl+C to stop")
-	fmt.Println("=======================================\n")
+	if *snapshotInterval > 0 && redisManager != nil {
+		go NewSnapshotter(dynamicClient, redisManager, servedResources, *snapshotInterval, watchOptions).Start(ctx)
+	}
 
 	// ========================================================================
 	// STEP 6: Start HTTP server (non-blocking)
 	// ========================================================================
-	go StartHTTPServer(redisManager, *httpPort)
+	// Admin endpoints (e.g. manual resync, rollback) are only exposed with --admin-endpoints
+	var adminManager *WatcherManager
+	if *adminEndpoints {
+		adminManager = watcherManager
+	}
+	go StartHTTPServer(redisManager, HTTPServerConfig{
+		Port:            *httpPort,
+		WatcherManager:  adminManager,
+		Pipeline:        pipeline,
+		ScanConcurrency: *scanConcurrency,
+		ScanBudget:      *scanBudget,
+		Broadcaster:     broadcaster,
+
+		ClusterScopedKinds: ClusterScopedKinds(discoveryClient, enabledResources),
+	})
 
-	// Block forever
-	select {}
+	// Block until SIGINT/SIGTERM
+	<-ctx.Done()
+	stop()
+
+	logInfo("\n🛑 Shutting down: stopping watchers and draining the pipeline...", "shutting down, draining pipeline")
+	if remaining := pipeline.Drain(*drainTimeout); remaining > 0 {
+		logWarn(fmt.Sprintf("⚠️  Drain timed out with %d events unprocessed", remaining),
+			"drain timed out", slog.Int64("unprocessed", remaining))
+	} else {
+		logInfo("✅ Pipeline drained", "pipeline drained")
+	}
+	// Deferred cleanup flushes watch checkpoints, flushes traces and closes Redis
 }
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/metadata"
+)
+
+// resourceClient is the subset of a namespaced resource client the watchers need.
+// dynamic.ResourceInterface satisfies it directly
+type resourceClient interface {
+	List(ctx context.Context, opts metav1.ListOptions) (*unstructured.UnstructuredList, error)
+	Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error)
+}
+
+// newResourceClient returns the client for one resource in one namespace: a metadata-only
+// client when opts.MetadataOnly is set, the dynamic client otherwise
+func newResourceClient(dynamicClient dynamic.Interface, gvr schema.GroupVersionResource, namespace string, kind string, opts WatchOptions) resourceClient {
+	if opts.MetadataOnly && opts.MetadataClient != nil {
+		return &metadataResourceClient{
+			client:     opts.MetadataClient.Resource(gvr).Namespace(namespace),
+			apiVersion: gvr.GroupVersion().String(),
+			kind:       kind,
+		}
+	}
+	return dynamicClient.Resource(gvr).Namespace(namespace)
+}
+
+// metadataResourceClient lists and watches PartialObjectMetadata, so only object metadata is
+// transferred, and hands the results on as unstructured objects carrying just apiVersion, kind
+// and metadata. Used for high-cardinality kinds where only labels/annotations matter
+type metadataResourceClient struct {
+	client     metadata.ResourceInterface
+	apiVersion string
+	kind       string
+}
+
+// List lists object metadata
+func (c *metadataResourceClient) List(ctx context.Context, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+	list, err := c.client.List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &unstructured.UnstructuredList{Object: map[string]interface{}{}}
+	result.SetResourceVersion(list.GetResourceVersion())
+	result.SetContinue(list.GetContinue())
+	for i := range list.Items {
+		obj, err := c.toUnstructured(&list.Items[i])
+		if err != nil {
+			return nil, err
+		}
+		result.Items = append(result.Items, *obj)
+	}
+	return result, nil
+}
+
+// Watch watches object metadata, converting each event's object to unstructured
+func (c *metadataResourceClient) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	watcher, err := c.client.Watch(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return watch.Filter(watcher, func(event watch.Event) (watch.Event, bool) {
+		partial, ok := event.Object.(*metav1.PartialObjectMetadata)
+		if !ok {
+			return event, true // errors (*metav1.Status) and unexpected types are handled by the watcher
+		}
+		obj, err := c.toUnstructured(partial)
+		if err != nil {
+			logWarn(fmt.Sprintf("⚠️  Failed to convert %s metadata: %v", c.kind, err),
+				"failed to convert metadata", slog.String("kind", c.kind), slog.String("error", err.Error()))
+			return event, false
+		}
+		event.Object = obj
+		return event, true
+	}), nil
+}
+
+// toUnstructured converts PartialObjectMetadata to an unstructured object of the watched kind
+func (c *metadataResourceClient) toUnstructured(partial *metav1.PartialObjectMetadata) (*unstructured.Unstructured, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(partial)
+	if err != nil {
+		return nil, err
+	}
+	obj := &unstructured.Unstructured{Object: content}
+	obj.SetAPIVersion(c.apiVersion)
+	obj.SetKind(c.kind)
+	return obj, nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Pipeline metrics, registered with the default Prometheus registry and served on /metrics
+var (
+	// pipelineEventsMetric counts events entering processEvent by kind and type
+	pipelineEventsMetric = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "pipeline_events_total",
+		Help: "Events processed by the pipeline.",
+	}, []string{"kind", "type"})
+
+	// pipelineLatencyMetric measures how long processEvent takes per event
+	pipelineLatencyMetric = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "pipeline_processing_seconds",
+		Help:    "Time spent processing one pipeline event.",
+		Buckets: []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5},
+	})
+)
+
+// recordPipelineEvent increments the event counter for a kind and event type
+func recordPipelineEvent(kind string, eventType EventType) {
+	pipelineEventsMetric.WithLabelValues(kind, string(eventType)).Inc()
+}
+
+// observePipelineLatency records how long one event took to process
+func observePipelineLatency(d time.Duration) {
+	pipelineLatencyMetric.Observe(d.Seconds())
+}
+
+// RegisterPipelineMetrics registers the pipeline_queue_depth gauge, read from the pipeline's
+// channel on each scrape
+func RegisterPipelineMetrics(pipeline *EventPipeline) {
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "pipeline_queue_depth",
+		Help: "Events waiting in the pipeline channel.",
+	}, func() float64 {
+		return float64(pipeline.QueueLength())
+	})
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// watchCastFailuresMetric counts watch events dropped because their object wasn't of the
+// expected type, by kind (watch_cast_failures_total{kind})
+var watchCastFailuresMetric = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "watch_cast_failures_total",
+	Help: "Watch or pipeline objects dropped for having an unexpected type, by kind.",
+}, []string{"kind"})
+
+// UnexpectedObjectError reports a watch or pipeline object that isn't of the type the code
+// expects, e.g. when the API server returns something else during version skew
+type UnexpectedObjectError struct {
+	Kind     string      // resource kind being watched
+	Scope    string      // namespace or "all namespaces" ("" when not known)
+	Context  string      // what was being handled, e.g. "MODIFIED event"
+	Expected string      // expected Go type
+	Object   interface{} // the object actually received
+}
+
+// Error implements error
+func (e *UnexpectedObjectError) Error() string {
+	where := e.Kind
+	if e.Scope != "" {
+		where += " in " + e.Scope
+	}
+	return fmt.Sprintf("unexpected object type %T in %s for %s (expected %s)", e.Object, e.Context, where, e.Expected)
+}
+
+// reportCastFailure logs an unexpected object at WARN and counts it, so version skew and
+// similar problems are visible instead of silently dropping events
+func reportCastFailure(err *UnexpectedObjectError) {
+	watchCastFailuresMetric.WithLabelValues(err.Kind).Inc()
+	logWarn(fmt.Sprintf("⚠️  WARN: %v - dropping it", err), "dropping object of unexpected type",
+		append(watchAttrs(err.Kind, err.Scope), slog.String("context", err.Context),
+			slog.String("type", fmt.Sprintf("%T", err.Object)), slog.String("expected", err.Expected))...)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ObjectEqualityFunc decides whether a modified object is equal to its previous state.
+// Equal modifications (e.g. a pure re-apply) are dropped before diffing and storage
+type ObjectEqualityFunc func(old, new *unstructured.Unstructured) bool
+
+// EqualityMode selects the built-in ObjectEqualityFunc
+type EqualityMode string
+
+const (
+	// EqualityIgnoreManagedFieldsTime ignores managedFields timestamps, which every apply bumps
+	// even when nothing changed, but still treats ownership changes as real
+	EqualityIgnoreManagedFieldsTime EqualityMode = "ignore-managed-fields-time"
+	// EqualityIgnoreManagedFields excludes managedFields from the comparison entirely
+	EqualityIgnoreManagedFields EqualityMode = "ignore-managed-fields"
+	// EqualityStrict disables the check: every modification is processed
+	EqualityStrict EqualityMode = "strict"
+)
+
+// EqualityFuncForMode returns the ObjectEqualityFunc for a mode (nil for strict)
+func EqualityFuncForMode(mode EqualityMode) (ObjectEqualityFunc, error) {
+	switch mode {
+	case EqualityIgnoreManagedFieldsTime:
+		return equalIgnoringManagedFieldsTime, nil
+	case EqualityIgnoreManagedFields:
+		return equalIgnoringManagedFields, nil
+	case EqualityStrict:
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unknown equality mode %q (expected %s, %s or %s)",
+		mode, EqualityIgnoreManagedFieldsTime, EqualityIgnoreManagedFields, EqualityStrict)
+}
+
+// equalIgnoringManagedFieldsTime compares two objects with managedFields times zeroed
+func equalIgnoringManagedFieldsTime(old, new *unstructured.Unstructured) bool {
+	return reflect.DeepEqual(
+		normalizeForEquality(old, zeroManagedFieldsTime),
+		normalizeForEquality(new, zeroManagedFieldsTime),
+	)
+}
+
+// equalIgnoringManagedFields compares two objects without their managedFields
+func equalIgnoringManagedFields(old, new *unstructured.Unstructured) bool {
+	return reflect.DeepEqual(
+		normalizeForEquality(old, dropManagedFields),
+		normalizeForEquality(new, dropManagedFields),
+	)
+}
+
+// normalizeForEquality returns a copy of obj without its resourceVersion (bumped by every write)
+// and with managedFields rewritten by normalizeManagedFields
+func normalizeForEquality(obj *unstructured.Unstructured, normalizeManagedFields func(*unstructured.Unstructured)) map[string]interface{} {
+	normalized := obj.DeepCopy()
+	normalized.SetResourceVersion("")
+	normalizeManagedFields(normalized)
+	return normalized.Object
+}
+
+// zeroManagedFieldsTime clears the time of every managedFields entry
+func zeroManagedFieldsTime(obj *unstructured.Unstructured) {
+	managedFields := obj.GetManagedFields()
+	for i := range managedFields {
+		managedFields[i].Time = nil
+	}
+	obj.SetManagedFields(managedFields)
+}
+
+// dropManagedFields removes managedFields
+func dropManagedFields(obj *unstructured.Unstructured) {
+	obj.SetManagedFields(nil)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// appliedRoute returns a test route last applied by manager at the given time
+func appliedRoute(resourceVersion string, hostname string, manager string, at time.Time) *unstructured.Unstructured {
+	obj := newTestRoute(resourceVersion, 1, hostname)
+	obj.SetManagedFields([]metav1.ManagedFieldsEntry{{
+		Manager:    manager,
+		Operation:  metav1.ManagedFieldsOperationApply,
+		APIVersion: "gateway.networking.k8s.io/v1",
+		Time:       &metav1.Time{Time: at},
+		FieldsType: "FieldsV1",
+		FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:hostnames":{}}}`)},
+	}})
+	return obj
+}
+
+func TestEqualityIgnoresManagedFieldsTime(t *testing.T) {
+	appliedAt := time.Date(2026, 2, 10, 6, 0, 0, 0, time.UTC)
+	old := appliedRoute("100", "web.example.com", "argocd-controller", appliedAt)
+
+	tests := []struct {
+		name  string
+		new   *unstructured.Unstructured
+		equal bool
+	}{
+		{"re-apply bumping only managedFields time", appliedRoute("101", "web.example.com", "argocd-controller", appliedAt.Add(time.Minute)), true},
+		{"spec change", appliedRoute("101", "api.example.com", "argocd-controller", appliedAt.Add(time.Minute)), false},
+		{"ownership change", appliedRoute("101", "web.example.com", "kubectl-client-side-apply", appliedAt.Add(time.Minute)), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := equalIgnoringManagedFieldsTime(old, tt.new); got != tt.equal {
+				t.Errorf("equalIgnoringManagedFieldsTime = %v, want %v", got, tt.equal)
+			}
+		})
+	}
+
+	// The comparison must not touch the objects: managedFields times still drive authorship
+	if old.GetManagedFields()[0].Time == nil {
+		t.Error("comparison cleared the original object's managedFields time")
+	}
+}
+
+func TestTimestampOnlyReapplyIsNotStored(t *testing.T) {
+	rm := newTestRedisManager(t)
+	ep := NewEventPipeline(10, rm)
+	appliedAt := time.Date(2026, 2, 10, 6, 0, 0, 0, time.UTC)
+
+	first := appliedRoute("100", "web.example.com", "argocd-controller", appliedAt)
+	ep.processEvent(ResourceEvent{Type: EventTypeAdded, ResourceKind: "HTTPRoute", Namespace: "default", Name: "web",
+		Object: first, ManagedFields: first.GetManagedFields()})
+
+	reapplied := appliedRoute("101", "web.example.com", "argocd-controller", appliedAt.Add(time.Minute))
+	recorded := recordChanges(ep)
+	ep.processEvent(ResourceEvent{Type: EventTypeModified, ResourceKind: "HTTPRoute", Namespace: "default", Name: "web",
+		Object: reapplied, ManagedFields: reapplied.GetManagedFields()})
+
+	if len(*recorded) != 0 {
+		t.Errorf("handlers called %d times for a timestamp-only re-apply, want 0", len(*recorded))
+	}
+	history, err := rm.GetResourceObjects("HTTPRoute/web/default")
+	if err != nil {
+		t.Fatalf("GetResourceObjects: %v", err)
+	}
+	if len(history) != 1 {
+		t.Errorf("stored %d versions, want 1", len(history))
+	}
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ownedKinds are the kinds a workload's pods are reached through via controller ownerReferences,
+// by workload kind
+var ownedKinds = map[string][]string{
+	"Deployment":  {"ReplicaSet", "Pod"},
+	"StatefulSet": {"Pod"},
+	"DaemonSet":   {"Pod"},
+	"Job":         {"Pod"},
+	"CronJob":     {"Job", "Pod"},
+}
+
+// ownedResources are the watches started for owned kinds that aren't configured explicitly.
+// Pods record status updates too, since their lifecycle (scheduling, readiness) lives in status
+var ownedResources = map[string]ResourceConfig{
+	"ReplicaSet": {Group: "apps", Version: "v1", Resource: "replicasets", Kind: "ReplicaSet", Enabled: true},
+	"Job":        {Group: "batch", Version: "v1", Resource: "jobs", Kind: "Job", Enabled: true},
+	"Pod":        {Group: "", Version: "v1", Resource: "pods", Kind: "Pod", Enabled: true, WatchStatus: true},
+}
+
+// maxPendingOwned bounds the events held while waiting for their owner to be seen
+const maxPendingOwned = 5000
+
+// ownerRecord is what the tracker knows about one observed object
+type ownerRecord struct {
+	key       string // resource key, Kind/name/namespace
+	root      bool   // a followOwned workload
+	rootOwner string // key of the topmost followOwned workload above the object ("" = none)
+}
+
+// OwnerTracker follows the objects owned by workloads configured with followOwned (e.g. a
+// Deployment's ReplicaSets and their Pods) by walking controller ownerReferences, and tags their
+// events with the root workload. Objects of kinds that are only watched on behalf of an owner are
+// dropped unless they belong to a followOwned workload
+type OwnerTracker struct {
+	roots     map[string][]string // followOwned kind -> namespaces (empty = all)
+	tracked   map[string]bool     // kinds whose ownership is recorded
+	followed  map[string]bool     // kinds watched only for their owners
+	resources []ResourceConfig    // implicit watches for the followed kinds
+
+	mutex        sync.Mutex
+	objects      map[types.UID]ownerRecord
+	pending      map[types.UID]map[types.UID]ResourceEvent // owner UID -> waiting events by object UID
+	pendingCount int
+}
+
+// NewOwnerTracker builds a tracker for the resources configured with followOwned. Returns nil
+// when none is
+func NewOwnerTracker(resources []ResourceConfig) (*OwnerTracker, error) {
+	configured := make(map[string]bool, len(resources))
+	for _, resource := range resources {
+		configured[resource.Kind] = true
+	}
+
+	ot := &OwnerTracker{
+		roots:    make(map[string][]string),
+		tracked:  make(map[string]bool),
+		followed: make(map[string]bool),
+		objects:  make(map[types.UID]ownerRecord),
+		pending:  make(map[types.UID]map[types.UID]ResourceEvent),
+	}
+	allNamespaces := make(map[string]bool)         // kinds with a config watching all namespaces
+	namespaces := make(map[string]map[string]bool) // followed kind -> namespaces to watch
+
+	for _, resource := range resources {
+		if !resource.FollowOwned {
+			continue
+		}
+		kinds, ok := ownedKinds[resource.Kind]
+		if !ok {
+			return nil, fmt.Errorf("followOwned is not supported for %s", resource.Kind)
+		}
+
+		ot.roots[resource.Kind] = append(ot.roots[resource.Kind], resource.Namespaces...)
+		ot.tracked[resource.Kind] = true
+		if len(resource.Namespaces) == 0 {
+			allNamespaces[resource.Kind] = true
+		}
+
+		for _, kind := range kinds {
+			ot.tracked[kind] = true
+			if configured[kind] {
+				continue // watched on its own account, only tagged
+			}
+			ot.followed[kind] = true
+			if len(resource.Namespaces) == 0 {
+				allNamespaces[kind] = true
+			}
+			if namespaces[kind] == nil {
+				namespaces[kind] = make(map[string]bool)
+			}
+			for _, namespace := range resource.Namespaces {
+				namespaces[kind][namespace] = true
+			}
+		}
+	}
+	if len(ot.roots) == 0 {
+		return nil, nil
+	}
+	for kind := range ot.roots {
+		if allNamespaces[kind] {
+			ot.roots[kind] = nil
+		}
+	}
+
+	for kind := range ot.followed {
+		resource := ownedResources[kind]
+		if !allNamespaces[kind] {
+			for namespace := range namespaces[kind] {
+				resource.Namespaces = append(resource.Namespaces, namespace)
+			}
+			sort.Strings(resource.Namespaces)
+		}
+		ot.resources = append(ot.resources, resource)
+	}
+	sort.Slice(ot.resources, func(i, j int) bool { return ot.resources[i].Kind < ot.resources[j].Kind })
+	return ot, nil
+}
+
+// SetOwnerTracker sets the tracker following objects owned by followOwned workloads (nil disables it)
+func (ep *EventPipeline) SetOwnerTracker(owners *OwnerTracker) {
+	ep.owners = owners
+}
+
+// Resources returns the watches to start for owned kinds that aren't configured explicitly
+func (ot *OwnerTracker) Resources() []ResourceConfig {
+	if ot == nil {
+		return nil
+	}
+	return ot.resources
+}
+
+// StatusKinds returns the followed kinds whose status updates are recorded
+func (ot *OwnerTracker) StatusKinds() []string {
+	kinds := make([]string, 0)
+	for _, resource := range ot.Resources() {
+		if resource.WatchStatus {
+			kinds = append(kinds, resource.Kind)
+		}
+	}
+	return kinds
+}
+
+// isRoot reports whether an object of the given kind and namespace is a followOwned workload
+func (ot *OwnerTracker) isRoot(kind, namespace string) bool {
+	namespaces, ok := ot.roots[kind]
+	if !ok {
+		return false
+	}
+	if len(namespaces) == 0 {
+		return true
+	}
+	for _, ns := range namespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve records the ownership of an event's object and sets event.RootOwner. It returns false
+// when the event should be dropped: an object of a followed kind that doesn't belong to a
+// followOwned workload, or whose owner hasn't been seen yet. The latter is held until its owner
+// is observed, and returned as released by that later Resolve call for the caller to process.
+// A nil tracker keeps every event
+func (ot *OwnerTracker) Resolve(event *ResourceEvent) (keep bool, released []ResourceEvent) {
+	if ot == nil || !ot.tracked[event.ResourceKind] {
+		return true, nil
+	}
+	accessor, err := meta.Accessor(event.Object)
+	if err != nil {
+		return !ot.followed[event.ResourceKind], nil
+	}
+	uid := accessor.GetUID()
+	followed := ot.followed[event.ResourceKind]
+
+	ot.mutex.Lock()
+	defer ot.mutex.Unlock()
+
+	// A deleted object keeps the root it had; anything still waiting for it never resolves
+	if event.Type == EventTypeDeleted {
+		record, known := ot.objects[uid]
+		delete(ot.objects, uid)
+		ot.pendingCount -= len(ot.pending[uid])
+		delete(ot.pending, uid)
+		event.RootOwner = record.rootOwner
+		return !followed || (known && record.rootOwner != ""), nil
+	}
+
+	record := ownerRecord{
+		key:  fmt.Sprintf("%s/%s/%s", event.ResourceKind, event.Name, event.Namespace),
+		root: ot.isRoot(event.ResourceKind, event.Namespace),
+	}
+	if ref := metav1.GetControllerOfNoCopy(accessor); ref != nil {
+		owner, known := ot.objects[ref.UID]
+		if !known && ot.hold(ref, uid, *event) {
+			return false, nil
+		}
+		record.rootOwner = owner.rootOwner
+		if record.rootOwner == "" && owner.root {
+			record.rootOwner = owner.key
+		}
+	}
+	ot.objects[uid] = record
+	event.RootOwner = record.rootOwner
+
+	// Objects that were waiting for this one can be resolved now
+	for _, waiting := range ot.pending[uid] {
+		released = append(released, waiting)
+	}
+	ot.pendingCount -= len(ot.pending[uid])
+	delete(ot.pending, uid)
+
+	return !followed || record.rootOwner != "", released
+}
+
+// hold keeps an event until its owner is observed, reporting whether it did. Only owners of a
+// tracked kind are waited for; the rest will never be seen. Must be called with the mutex held
+func (ot *OwnerTracker) hold(owner *metav1.OwnerReference, uid types.UID, event ResourceEvent) bool {
+	if !ot.tracked[owner.Kind] {
+		return false
+	}
+	waiting := ot.pending[owner.UID]
+	previous, replacing := waiting[uid]
+	if !replacing && ot.pendingCount >= maxPendingOwned {
+		return false
+	}
+	if waiting == nil {
+		waiting = make(map[types.UID]ResourceEvent)
+		ot.pending[owner.UID] = waiting
+	}
+	// Keep only the latest state, but don't lose that the object was added
+	if replacing && previous.Type == EventTypeAdded {
+		event.Type = EventTypeAdded
+	}
+	waiting[uid] = event
+	if !replacing {
+		ot.pendingCount++
+	}
+	return true
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 )
@@ -18,6 +19,9 @@ func QueryChanges(redisManager *RedisManager, numChanges int) error {
 		return err
 	}
 
+	if redisManager.DryRun() {
+		fmt.Println("🧪 Dry-run mode: nothing is stored, the queue only holds changes recorded by earlier runs")
+	}
 	fmt.Printf("📊 Total annotation changes in queue: %d\n", size)
 
 	// Print last n changes
@@ -28,14 +32,19 @@ func QueryChanges(redisManager *RedisManager, numChanges int) error {
 	return nil
 }
 
-// CLI function to query from command line
-func QueryChangesFromCLI(redisAddr string, numChanges int) {
-	redisManager, err := NewRedisManager(redisAddr, "annotation_changes", 1000)
+// CLI function to query from command line. dryRun matches the watcher's --dry-run
+func QueryChangesFromCLI(redisConfig RedisConfig, numChanges int, dryRun bool) {
+	redisManager, err := NewRedisManager(redisConfig, "annotation_changes", 1000, 0)
+	if errors.Is(err, ErrRedisUnavailable) {
+		fmt.Printf("❌ Cannot query changes, Redis is unavailable: %v\n", err)
+		os.Exit(1)
+	}
 	if err != nil {
-		fmt.Printf("❌ Failed to connect to Redis: %v\n", err)
+		fmt.Printf("❌ Failed to initialize Redis: %v\n", err)
 		os.Exit(1)
 	}
 	defer redisManager.Close()
+	redisManager.SetDryRun(dryRun)
 
 	if err := QueryChanges(redisManager, numChanges); err != nil {
 		os.Exit(1)
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// selfSubjectAccessReviewGVR is created (not stored) to ask the API server what we may do
+var selfSubjectAccessReviewGVR = schema.GroupVersionResource{
+	Group:    "authorization.k8s.io",
+	Version:  "v1",
+	Resource: "selfsubjectaccessreviews",
+}
+
+// watchVerbs are the verbs a watcher needs on a resource
+var watchVerbs = []string{"list", "watch"}
+
+// CheckWatchAccess asks the API server via SelfSubjectAccessReview whether the current identity
+// may list and watch the resource in each of its configured namespaces (or cluster-wide).
+// Returns a reason when access is denied, or "" when every check is allowed
+func CheckWatchAccess(ctx context.Context, dynamicClient dynamic.Interface, resource ResourceConfig) (string, error) {
+	scopes := resource.Namespaces
+	if len(scopes) == 0 {
+		scopes = []string{metav1.NamespaceAll}
+	}
+
+	for _, scope := range scopes {
+		for _, verb := range watchVerbs {
+			allowed, reason, err := reviewAccess(ctx, dynamicClient, &authorizationv1.ResourceAttributes{
+				Namespace: scope,
+				Verb:      verb,
+				Group:     resource.Group,
+				Version:   resource.Version,
+				Resource:  resource.Resource,
+			})
+			if err != nil {
+				return "", err
+			}
+			if !allowed {
+				where := "all namespaces"
+				if scope != metav1.NamespaceAll {
+					where = "namespace " + scope
+				}
+				if reason == "" {
+					reason = "no RBAC rule grants it"
+				}
+				return fmt.Sprintf("cannot %s in %s: %s", verb, where, reason), nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// FilterWatchableResources drops the resources the current identity may not list/watch, printing
+// a single warning for each. Resources whose access couldn't be checked are kept.
+// Returns the permitted resources and the kinds that were disabled
+func FilterWatchableResources(ctx context.Context, dynamicClient dynamic.Interface, resources []ResourceConfig) ([]ResourceConfig, []string) {
+	permitted := make([]ResourceConfig, 0, len(resources))
+	disabled := make([]string, 0)
+
+	for _, resource := range resources {
+		reason, err := CheckWatchAccess(ctx, dynamicClient, resource)
+		if err != nil {
+			logWarn(fmt.Sprintf("      ⚠️  %s: RBAC check failed: %v (watching anyway)", resource.Kind, err),
+				"RBAC check failed, watching anyway", slog.String("kind", resource.Kind), slog.String("error", err.Error()))
+			permitted = append(permitted, resource)
+			continue
+		}
+		if reason != "" {
+			logWarn(fmt.Sprintf("      ✗ %s (%s/%s/%s) - Forbidden: %s, skipping",
+				resource.Kind, resource.Group, resource.Version, resource.Resource, reason),
+				"watch forbidden by RBAC, skipping", slog.String("kind", resource.Kind), slog.String("reason", reason))
+			disabled = append(disabled, resource.Kind)
+			continue
+		}
+		permitted = append(permitted, resource)
+	}
+
+	return permitted, disabled
+}
+
+// reviewAccess submits a SelfSubjectAccessReview and returns whether it was allowed and why not
+func reviewAccess(ctx context.Context, dynamicClient dynamic.Interface, attributes *authorizationv1.ResourceAttributes) (bool, string, error) {
+	review := &authorizationv1.SelfSubjectAccessReview{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: authorizationv1.SchemeGroupVersion.String(),
+			Kind:       "SelfSubjectAccessReview",
+		},
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: attributes},
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(review)
+	if err != nil {
+		return false, "", fmt.Errorf("failed to convert access review: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	created, err := dynamicClient.Resource(selfSubjectAccessReviewGVR).Create(ctx, &unstructured.Unstructured{Object: content}, metav1.CreateOptions{})
+	if err != nil {
+		return false, "", fmt.Errorf("failed to review %s access to %s: %w", attributes.Verb, attributes.Resource, err)
+	}
+
+	var result authorizationv1.SelfSubjectAccessReview
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(created.Object, &result); err != nil {
+		return false, "", fmt.Errorf("failed to decode access review: %w", err)
+	}
+	return result.Status.Allowed, result.Status.Reason, nil
+}
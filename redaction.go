@@ -0,0 +1,208 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// redactedValuePrefix starts the digest replacing the value of every redacted field
+const redactedValuePrefix = "<redacted:hmac-sha256:"
+
+// redactedMarker starts every redacted value, including those stored by earlier versions
+const redactedMarker = "<redacted"
+
+// redactionKey keys the digests of redacted values, see SetRedactionKey
+var redactionKey = newRandomRedactionKey()
+
+// lastAppliedAnnotation is where kubectl apply keeps the whole applied object, Secret data included
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// defaultRedactedFields are redacted whatever the configuration: Secret payloads, including the
+// copy kubectl apply leaves in the last-applied-configuration annotation
+var defaultRedactedFields = map[string][]string{
+	"Secret": {"data", "stringData", "metadata.annotations." + lastAppliedAnnotation},
+}
+
+// redactedFields are the dotted field paths redacted per kind ("" = every kind), see SetRedactedFields
+var redactedFields = defaultRedactedFields
+
+// RedactedFields returns the fields to redact per kind: the defaults, the resources'
+// redactFields, and global paths applying to every kind
+func (wc *WatcherConfig) RedactedFields(global []string) map[string][]string {
+	fields := make(map[string][]string, len(defaultRedactedFields))
+	for kind, paths := range defaultRedactedFields {
+		fields[kind] = append(fields[kind], paths...)
+	}
+	for _, resource := range wc.Resources {
+		fields[resource.Kind] = append(fields[resource.Kind], resource.RedactFields...)
+	}
+	fields[""] = append(fields[""], global...)
+	return fields
+}
+
+// SetRedactedFields sets the dotted field paths (e.g. spec.tls.privateKey) replaced with
+// a digest of their value, per kind ("" = every kind). Objects are redacted by redactObject as they are
+// received from the API server, so their values never reach the pipeline, Redis, logs or the
+// APIs, and when cleaned for display. Call it before any watcher starts
+func SetRedactedFields(fields map[string][]string) {
+	redactedFields = fields
+}
+
+// SetRedactionKey sets the secret keying the digests of redacted values. Without it a random key
+// is generated per process: digests then can't be checked against guessed values, but every
+// redacted value reads as changed once after a restart. Call it before any watcher starts
+func SetRedactionKey(key string) {
+	if key == "" {
+		redactionKey = newRandomRedactionKey()
+		return
+	}
+	redactionKey = []byte(key)
+}
+
+// newRandomRedactionKey returns a random 32-byte key
+func newRandomRedactionKey() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(fmt.Sprintf("failed to generate redaction key: %v", err))
+	}
+	return key
+}
+
+// redactPaths returns the paths redacted for a kind
+func redactPaths(kind string) []string {
+	return append(redactedFields[kind], redactedFields[""]...)
+}
+
+// hasRedactedFields reports whether any field of a kind is redacted
+func hasRedactedFields(kind string) bool {
+	return len(redactPaths(kind)) > 0
+}
+
+// redactObject returns a copy of obj with the redacted fields of its kind replaced, or obj itself
+// when none of them is set. Every path receiving objects from the API server (watches, informers,
+// Lists, resyncs and baseline snapshots) calls it before logging, queueing or storing the object
+func redactObject(obj *unstructured.Unstructured) *unstructured.Unstructured {
+	paths := redactPaths(obj.GetKind())
+	if len(paths) == 0 {
+		return obj
+	}
+	var redacted *unstructured.Unstructured
+	for _, path := range paths {
+		fields := fieldPathSegments(obj.Object, path)
+		if fields == nil {
+			continue
+		}
+		if redacted == nil {
+			redacted = obj.DeepCopy()
+		}
+		redactField(redacted.Object, fields)
+	}
+	if redacted == nil {
+		return obj
+	}
+	return redacted
+}
+
+// redactMap replaces the redacted fields of an object map in place
+func redactMap(objMap map[string]interface{}) {
+	kind, _ := objMap["kind"].(string)
+	for _, path := range redactPaths(kind) {
+		if fields := fieldPathSegments(objMap, path); fields != nil {
+			redactField(objMap, fields)
+		}
+	}
+}
+
+// fieldPathSegments splits a dotted field path into the keys it names in objMap, matching keys
+// that contain dots themselves (e.g. the annotation in
+// metadata.annotations.kubectl.kubernetes.io/last-applied-configuration) as a whole. Returns nil
+// when the path isn't set
+func fieldPathSegments(objMap map[string]interface{}, path string) []string {
+	parts := strings.Split(path, ".")
+	segments := make([]string, 0, len(parts))
+	current := objMap
+	for i := 0; i < len(parts); {
+		if current == nil {
+			return nil
+		}
+		// The longest matching key wins, so a dotted key isn't split
+		end := 0
+		for j := len(parts); j > i; j-- {
+			if _, ok := current[strings.Join(parts[i:j], ".")]; ok {
+				end = j
+				break
+			}
+		}
+		if end == 0 {
+			return nil
+		}
+		key := strings.Join(parts[i:end], ".")
+		segments = append(segments, key)
+		current, _ = current[key].(map[string]interface{})
+		i = end
+	}
+	return segments
+}
+
+// redactField replaces the value at fields, if set. A map (e.g. a Secret's data) keeps its keys
+// with each value replaced, so added and removed keys still show up in diffs
+func redactField(objMap map[string]interface{}, fields []string) {
+	value, found, _ := unstructured.NestedFieldNoCopy(objMap, fields...)
+	if !found {
+		return
+	}
+	if entries, ok := value.(map[string]interface{}); ok {
+		for key, entry := range entries {
+			entries[key] = redactedValue(entry)
+		}
+		return
+	}
+	unstructured.SetNestedField(objMap, redactedValue(value), fields...)
+}
+
+// redactedValue returns the digest replacing a value, e.g. "<redacted:hmac-sha256:1f0c3a9e5b7d2468>":
+// the first 8 bytes of an HMAC-SHA256 of its JSON keyed by SetRedactionKey. The same value gets
+// the same digest under one key, so a changed value still shows up as a change, while guessed
+// values can't be checked without the key. A value already redacted is returned as is
+func redactedValue(value interface{}) string {
+	if redacted, ok := value.(string); ok && strings.HasPrefix(redacted, redactedMarker) {
+		return redacted
+	}
+	data, _ := json.Marshal(value)
+	mac := hmac.New(sha256.New, redactionKey)
+	mac.Write(data)
+	return fmt.Sprintf("%s%x>", redactedValuePrefix, mac.Sum(nil)[:8])
+}
+
+// isRedacted reports whether a field value was replaced by redactField
+func isRedacted(value interface{}) bool {
+	if entries, ok := value.(map[string]interface{}); ok {
+		for _, entry := range entries {
+			if isRedacted(entry) {
+				return true
+			}
+		}
+		return false
+	}
+	redacted, ok := value.(string)
+	return ok && strings.HasPrefix(redacted, redactedMarker)
+}
+
+// ParseFieldPaths parses a comma-separated list of dotted field paths (e.g. "spec.tls.privateKey,data")
+func ParseFieldPaths(value string) []string {
+	paths := make([]string, 0)
+	for _, part := range strings.Split(value, ",") {
+		path := strings.Trim(strings.TrimSpace(part), ".")
+		if path == "" {
+			continue
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
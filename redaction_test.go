@@ -0,0 +1,139 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// appliedObject returns an object of kind default/app with data and a last-applied-configuration
+// annotation holding the same data, as kubectl apply leaves it
+func appliedObject(kind string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       kind,
+		"metadata": map[string]interface{}{
+			"name":      "app",
+			"namespace": "default",
+			"annotations": map[string]interface{}{
+				lastAppliedAnnotation: `{"data":{"password":"aHVudGVyMg=="}}`,
+				"team":                "payments",
+			},
+		},
+		"data": map[string]interface{}{"password": "aHVudGVyMg=="},
+	}}
+}
+
+func TestRedactObjectCoversLastAppliedConfiguration(t *testing.T) {
+	received := appliedObject("Secret")
+	redacted := redactObject(received)
+
+	annotations := redacted.GetAnnotations()
+	if !isRedacted(annotations[lastAppliedAnnotation]) {
+		t.Errorf("last-applied-configuration = %q, want it redacted", annotations[lastAppliedAnnotation])
+	}
+	if annotations["team"] != "payments" {
+		t.Errorf("team annotation = %q, want it kept", annotations["team"])
+	}
+	if data, _, _ := unstructured.NestedFieldNoCopy(redacted.Object, "data"); !isRedacted(data) {
+		t.Errorf("data = %v, want it redacted", data)
+	}
+
+	// Informers hand out their cached objects, which must stay untouched
+	if received.GetAnnotations()[lastAppliedAnnotation] == annotations[lastAppliedAnnotation] {
+		t.Error("redactObject modified the received object")
+	}
+
+	// Other kinds keep the annotation
+	configMap := appliedObject("ConfigMap")
+	if redactObject(configMap) != configMap {
+		t.Error("redactObject copied a ConfigMap with nothing to redact")
+	}
+}
+
+func TestFieldPathSegmentsMatchesDottedKeys(t *testing.T) {
+	obj := appliedObject("Secret").Object
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{"data.password", []string{"data", "password"}},
+		{"metadata.annotations." + lastAppliedAnnotation, []string{"metadata", "annotations", lastAppliedAnnotation}},
+		{"metadata.annotations.missing.key", nil},
+		{"spec.tls", nil},
+	}
+	for _, tt := range tests {
+		got := fieldPathSegments(obj, tt.path)
+		if len(got) != len(tt.want) {
+			t.Errorf("fieldPathSegments(%q) = %q, want %q", tt.path, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("fieldPathSegments(%q) = %q, want %q", tt.path, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestRedactedValueChangesWithTheValue(t *testing.T) {
+	old := redactObject(appliedObject("Secret"))
+	changed := appliedObject("Secret")
+	unstructured.SetNestedField(changed.Object, "bmV3LXBhc3N3b3Jk", "data", "password")
+	new := redactObject(changed)
+
+	oldValue, _, _ := unstructured.NestedString(old.Object, "data", "password")
+	newValue, _, _ := unstructured.NestedString(new.Object, "data", "password")
+	if !isRedacted(oldValue) || !isRedacted(newValue) {
+		t.Fatalf("data.password = %q → %q, want both redacted", oldValue, newValue)
+	}
+	if oldValue == newValue {
+		t.Errorf("data.password digest %q didn't change with the value", oldValue)
+	}
+	if again := redactObject(appliedObject("Secret")); !reflect.DeepEqual(again.Object, old.Object) {
+		t.Error("the same Secret redacted twice got different digests")
+	}
+
+	// Redacting a redacted object (e.g. history cleaned for display) keeps its digests
+	if twice := redactObject(old); !reflect.DeepEqual(twice.Object, old.Object) {
+		t.Error("redacting a redacted object changed its digests")
+	}
+
+	changes, err := GetFieldChanges(old.Object, new.Object)
+	if err != nil {
+		t.Fatalf("GetFieldChanges: %v", err)
+	}
+	if len(changes) != 1 || changes[0].Path != "data.password" {
+		t.Errorf("changes = %+v, want only data.password", changes)
+	}
+}
+
+func TestRedactedValueNeedsTheKey(t *testing.T) {
+	defer SetRedactionKey("")
+	SetRedactionKey("cluster-a-key")
+	digest := redactedValue("hunter2")
+
+	// An unkeyed hash of a guessed value doesn't match the stored digest
+	data, _ := json.Marshal("hunter2")
+	unkeyed := sha256.Sum256(data)
+	if digest == fmt.Sprintf("%s%x>", redactedValuePrefix, unkeyed[:8]) {
+		t.Errorf("digest %q is an unkeyed SHA-256 of the value", digest)
+	}
+
+	// Nor does the digest computed under another key
+	SetRedactionKey("guessed-key")
+	if other := redactedValue("hunter2"); other == digest {
+		t.Errorf("digest %q is the same under a different key", digest)
+	}
+
+	// The same key reproduces it, so unchanged values keep their digest across restarts
+	SetRedactionKey("cluster-a-key")
+	if again := redactedValue("hunter2"); again != digest {
+		t.Errorf("digest = %q under the same key, want %q", again, digest)
+	}
+}
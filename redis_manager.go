@@ -2,8 +2,14 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"net/url"
+	"sync"
 	"time"
 
 	"github.com/go-redis/redis/v8"
@@ -11,13 +17,20 @@ import (
 
 // ResourceChange represents a single resource change with versioning
 type ResourceChange struct {
-	Version      int64                  `json:"version"` // Version number (1, 2, 3...)
-	ResourceKind string                 `json:"resource_kind"`
-	Namespace    string                 `json:"namespace"`
-	ResourceName string                 `json:"resource_name"`
-	Timestamp    time.Time              `json:"timestamp"`
-	Object       interface{}            `json:"object"`  // Full object snapshot
-	Changes      map[string]interface{} `json:"changes"` // What changed from previous version
+	Version       int64                  `json:"version"` // Version number (1, 2, 3...)
+	ResourceKind  string                 `json:"resource_kind"`
+	Namespace     string                 `json:"namespace"`
+	ResourceName  string                 `json:"resource_name"`
+	Timestamp     time.Time              `json:"timestamp"`
+	Object        interface{}            `json:"object"`                   // Full object snapshot
+	Changes       map[string]interface{} `json:"changes"`                  // What changed from previous version
+	FieldChanges  []FieldChange          `json:"field_changes,omitempty"`  // Per-field diff against the previous stored version, see DiffAgainstPrevious
+	CorrelationID string                 `json:"correlation_id,omitempty"` // Groups changes from one rollout, see SetCorrelationAnnotation
+	FocusFields   map[string]string      `json:"focus_fields,omitempty"`   // Per-kind one-line summary fields, see SetFocusFields
+	ImageChanges  []ImageChange          `json:"image_changes,omitempty"`  // Container image updates of a workload
+	RouteChanges  []string               `json:"route_changes,omitempty"`  // Route rule changes, e.g. "added match Greeter/SayHello → backend api:50051"
+	RootOwner     string                 `json:"root_owner,omitempty"`     // Resource key of the followOwned workload owning the object
+	DiffSkipped   string                 `json:"diff_skipped,omitempty"`   // Why the diff was abandoned, see SetDiffLimits
 }
 
 // RedisManager manages Redis queue operations for resource changes
@@ -25,44 +38,197 @@ type RedisManager struct {
 	client    *redis.Client
 	queueName string
 	maxSize   int
+	ttl       time.Duration                  // expiry of per-resource keys after their last write (0 = never)
+	keyLocks  [resourceLockShards]sync.Mutex // sharded per-resource locks, see LockResource
+
+	correlationAnnotation string         // annotation whose value groups changes, see SetCorrelationAnnotation
+	recentChangesFeed     bool           // also push changes onto the global queue, see SetRecentChangesFeed
+	focusFields           FocusFieldSet  // summary fields evaluated at push time, see SetFocusFields
+	fullObjectEvery       map[string]int // per kind, store a full object every K versions, see SetFullObjectPolicy
+	dryRun                bool           // never write, see SetDryRun
 }
 
+// changesKeyPrefix prefixes the per-resource change lists written by PushResourceChange
+const changesKeyPrefix = "changes:"
+
+// resourceLockShards is the number of mutexes resource keys are hashed onto
+const resourceLockShards = 64
+
 // StoredObject wraps a Kubernetes object with storage metadata
 type StoredObject struct {
-	Object           interface{} `json:"object"`            // The actual Kubernetes object
-	StoredTimestamp  string      `json:"stored_timestamp"`  // When this version was stored in Redis
+	Object          interface{}       `json:"object"`                    // The actual Kubernetes object
+	StoredTimestamp string            `json:"stored_timestamp"`          // When this version was stored in Redis
+	Baseline        bool              `json:"baseline,omitempty"`        // Scheduled point-in-time snapshot rather than a change
+	ChangedBy       string            `json:"changed_by,omitempty"`      // Field manager the change is attributed to
+	CorrelationID   string            `json:"correlation_id,omitempty"`  // Value of the correlation annotation, see SetCorrelationAnnotation
+	FocusFields     map[string]string `json:"focus_fields,omitempty"`    // Per-kind one-line summary fields, see SetFocusFields
+	RootOwner       string            `json:"root_owner,omitempty"`      // Resource key of the followOwned workload owning the object
+	Patch           interface{}       `json:"patch,omitempty"`           // Merge patch against the previous version; Object then holds only metadata, see SetFullObjectPolicy
+	DiffSkipped     string            `json:"diff_skipped,omitempty"`    // Why the diff against the previous version was abandoned, see SetDiffLimits
+	StoredVersion   int64             `json:"stored_version,omitempty"`  // Per-resource version number, set for objects without a generation and used as their generation key
+	CoalescedCount  int               `json:"coalesced_count,omitempty"` // Raw watch events debounced into this version, set when more than one
+	CoalescedFrom   string            `json:"coalesced_from,omitempty"`  // When the first of the coalesced events was received
+	CoalescedUntil  string            `json:"coalesced_until,omitempty"` // When the last of the coalesced events was received
 }
 
-// NewRedisManager creates a new Redis manager
-func NewRedisManager(redisAddr string, queueName string, maxSize int) (*RedisManager, error) {
-	client := redis.NewClient(&redis.Options{
-		Addr: redisAddr,
-	})
+// ErrRedisUnavailable is returned by NewRedisManager when Redis can't be reached
+var ErrRedisUnavailable = errors.New("redis unavailable")
+
+// RedisConfig holds how to connect to Redis
+type RedisConfig struct {
+	Addr            string        // host:port
+	Password        string        // AUTH password (empty = no auth)
+	DB              int           // database index
+	TLS             bool          // connect over TLS
+	DialTimeout     time.Duration // per-connection dial timeout (0 = client default of 5s)
+	ConnectAttempts int           // initial pings tried before giving up (<1 = 1)
+	MaxRetries      int           // retries of a failed command on transient errors (0 = client default of 3)
+}
 
-	// Test connection
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+// redisConnectBackoff is the delay before the second connection attempt; it doubles up to redisConnectMaxBackoff
+const (
+	redisConnectBackoff    = time.Second
+	redisConnectMaxBackoff = 10 * time.Second
+)
 
-	if err := client.Ping(ctx).Err(); err != nil {
-		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+// NewRedisManager creates a new Redis manager, retrying the initial connection up to
+// cfg.ConnectAttempts times. Per-resource keys expire ttl after their last write; a ttl of 0
+// keeps them forever
+func NewRedisManager(cfg RedisConfig, queueName string, maxSize int, ttl time.Duration) (*RedisManager, error) {
+	options := &redis.Options{
+		Addr:        cfg.Addr,
+		Password:    cfg.Password,
+		DB:          cfg.DB,
+		DialTimeout: cfg.DialTimeout,
+		MaxRetries:  cfg.MaxRetries,
+	}
+	if cfg.TLS {
+		options.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	client := redis.NewClient(options)
+
+	if err := pingWithRetry(client, cfg.ConnectAttempts); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("%w at %s: %v", ErrRedisUnavailable, cfg.Addr, err)
 	}
 
 	return &RedisManager{
-		client:    client,
-		queueName: queueName,
-		maxSize:   maxSize,
+		client:            client,
+		queueName:         queueName,
+		maxSize:           maxSize,
+		ttl:               ttl,
+		recentChangesFeed: true,
 	}, nil
 }
 
+// pingWithRetry pings Redis up to attempts times with exponential backoff, so a Redis that is
+// briefly unavailable at startup (e.g. still starting in the same pod) isn't fatal
+func pingWithRetry(client *redis.Client, attempts int) error {
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	backoff := redisConnectBackoff
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		err = client.Ping(ctx).Err()
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if attempt == attempts {
+			break
+		}
+
+		logWarn(fmt.Sprintf("⚠️  Redis ping failed (attempt %d/%d): %v - retrying in %v", attempt, attempts, err, backoff),
+			"Redis ping failed, retrying", slog.Int("attempt", attempt), slog.Int("attempts", attempts),
+			slog.String("error", err.Error()), slog.Duration("backoff", backoff))
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > redisConnectMaxBackoff {
+			backoff = redisConnectMaxBackoff
+		}
+	}
+	return err
+}
+
+// LockResource locks the shard for a resource key and returns the unlock function.
+// Hold it across read-latest + version-assign + push so one resource's history is strictly
+// ordered, while distinct resources (on other shards) stay parallel
+func (rm *RedisManager) LockResource(resourceKey string) func() {
+	hash := fnv.New32a()
+	hash.Write([]byte(resourceKey))
+	lock := &rm.keyLocks[hash.Sum32()%resourceLockShards]
+	lock.Lock()
+	return lock.Unlock
+}
+
 // PushObject pushes a direct object to a resource-specific key (kind/name/namespace)
 func (rm *RedisManager) PushObject(resourceKey string, obj interface{}) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	return rm.PushTaggedObject(resourceKey, obj, ObjectTags{})
+}
+
+// ObjectTags are the pipeline's annotations of a stored version
+type ObjectTags struct {
+	RootOwner   string // resource key of the followOwned workload owning the object
+	DiffSkipped string // why the diff was abandoned for exceeding the DiffLimits
 
+	CoalescedCount int       // raw watch events debounced into the version (0 or 1 = a single event)
+	CoalescedFrom  time.Time // first of the coalesced events
+	CoalescedUntil time.Time // last of the coalesced events
+}
+
+// PushTaggedObject is PushObject with the pipeline's tags recorded alongside the object
+func (rm *RedisManager) PushTaggedObject(resourceKey string, obj interface{}, tags ObjectTags) error {
 	// Wrap object with storage timestamp
 	storedObj := StoredObject{
 		Object:          obj,
 		StoredTimestamp: time.Now().UTC().Format(time.RFC3339),
+		ChangedBy:       changeAuthor(obj),
+		CorrelationID:   rm.correlationID(obj),
+		RootOwner:       tags.RootOwner,
+		DiffSkipped:     tags.DiffSkipped,
+	}
+	if tags.CoalescedCount > 1 {
+		storedObj.CoalescedCount = tags.CoalescedCount
+		storedObj.CoalescedFrom = tags.CoalescedFrom.UTC().Format(time.RFC3339Nano)
+		storedObj.CoalescedUntil = tags.CoalescedUntil.UTC().Format(time.RFC3339Nano)
+	}
+	return rm.pushStoredObject(resourceKey, storedObj)
+}
+
+// PushBaselineObject stores a scheduled baseline snapshot of a resource, tagged so it can be
+// told apart from change-driven versions
+func (rm *RedisManager) PushBaselineObject(resourceKey string, obj interface{}) error {
+	return rm.pushStoredObject(resourceKey, StoredObject{
+		Object:          obj,
+		StoredTimestamp: time.Now().UTC().Format(time.RFC3339),
+		Baseline:        true,
+	})
+}
+
+// pushStoredObject pushes a wrapped object onto a resource's history and trims it to maxSize
+func (rm *RedisManager) pushStoredObject(resourceKey string, storedObj StoredObject) error {
+	if rm.dryRun {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if storedObj.FocusFields == nil {
+		storedObj.FocusFields = rm.focusFields.Evaluate(storedObj.Object)
+	}
+	if getObjectGenerationFromEvent(storedObj.Object) == 0 {
+		version, err := rm.nextStoredVersion(ctx, resourceKey)
+		if err != nil {
+			return err
+		}
+		storedObj.StoredVersion = version
+	}
+	oldestFull, err := rm.applyFullObjectPolicy(ctx, resourceKey, &storedObj)
+	if err != nil {
+		return err
 	}
 
 	// Marshal wrapped object to JSON
@@ -80,23 +246,92 @@ func (rm *RedisManager) PushObject(resourceKey string, obj interface{}) error {
 	if err := rm.client.LTrim(ctx, resourceKey, 0, int64(rm.maxSize-1)).Err(); err != nil {
 		return fmt.Errorf("failed to trim resource key %s: %w", resourceKey, err)
 	}
+	if oldestFull != "" {
+		if err := rm.client.LSet(ctx, resourceKey, -1, oldestFull).Err(); err != nil {
+			return fmt.Errorf("failed to store full oldest version of %s: %w", resourceKey, err)
+		}
+	}
+	if err := rm.expireResourceKey(ctx, resourceKey); err != nil {
+		return err
+	}
+
+	if storedObj.CorrelationID != "" {
+		if err := rm.indexCorrelation(ctx, resourceKey, storedObj); err != nil {
+			return err
+		}
+	}
+	if storedObj.FocusFields != nil {
+		if err := rm.saveFocusFields(ctx, resourceKey, storedObj.FocusFields); err != nil {
+			return err
+		}
+	}
 
-	rm.logObject(obj)
 	return nil
 }
 
-// PushResourceChange pushes a new resource change to the global change queue
-// Queue has fixed size - oldest changes are automatically removed when queue is full
+// expireResourceKey (re)sets the expiry of a per-resource key when a TTL is configured
+func (rm *RedisManager) expireResourceKey(ctx context.Context, key string) error {
+	if rm.ttl <= 0 {
+		return nil
+	}
+	if err := rm.client.Expire(ctx, key, rm.ttl).Err(); err != nil {
+		return fmt.Errorf("failed to set expiry on %s: %w", key, err)
+	}
+	return nil
+}
+
+// SetRecentChangesFeed sets whether PushResourceChange also pushes each change onto the global
+// queue, which serves as a recent-activity feed across all resources (enabled by default)
+func (rm *RedisManager) SetRecentChangesFeed(enabled bool) {
+	rm.recentChangesFeed = enabled
+}
+
+// SetDryRun sets whether every write is skipped: pushes, history rewrites and watch checkpoints
+// succeed without touching Redis, while reads keep working against what earlier runs stored
+func (rm *RedisManager) SetDryRun(enabled bool) {
+	rm.dryRun = enabled
+}
+
+// DryRun reports whether writes are skipped, see SetDryRun
+func (rm *RedisManager) DryRun() bool {
+	return rm.dryRun
+}
+
+// changesKey returns the Redis key of a resource's change list. The resource key is escaped so
+// the list isn't mistaken for a kind/name/namespace history key
+func changesKey(resourceKey string) string {
+	return changesKeyPrefix + url.PathEscape(resourceKey)
+}
+
+// PushResourceChange pushes a new resource change to the resource's change list, and to the
+// global recent-activity queue when enabled. Both have a fixed size - the oldest changes are
+// removed once maxSize is reached
 func (rm *RedisManager) PushResourceChange(resourceKey string, change ResourceChange) error {
+	unlock := rm.LockResource(resourceKey)
+	defer unlock()
+	return rm.pushResourceChange(resourceKey, change)
+}
+
+// pushResourceChange is PushResourceChange for callers already holding LockResource
+func (rm *RedisManager) pushResourceChange(resourceKey string, change ResourceChange) error {
+	if rm.dryRun {
+		return nil
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	// Get current version for this resource
-	version, err := rm.GetCurrentVersion(resourceKey)
+	// Assign the next version for this resource
+	version, err := rm.nextVersion(ctx, resourceKey)
 	if err != nil {
-		return fmt.Errorf("failed to get current version: %w", err)
+		return fmt.Errorf("failed to assign version: %w", err)
+	}
+	change.Version = version
+	if change.CorrelationID == "" {
+		change.CorrelationID = rm.correlationID(change.Object)
+	}
+	if change.FocusFields == nil {
+		change.FocusFields = rm.focusFields.Evaluate(change.Object)
 	}
-	change.Version = version + 1
 
 	// Marshal change to JSON
 	data, err := json.Marshal(change)
@@ -104,36 +339,44 @@ func (rm *RedisManager) PushResourceChange(resourceKey string, change ResourceCh
 		return fmt.Errorf("failed to marshal change: %w", err)
 	}
 
-	// Push to queue (LPUSH adds to the beginning - most recent first)
-	// Queue key: resource_changes (all changes from all resources)
-	if err := rm.client.LPush(ctx, rm.queueName, string(data)).Err(); err != nil {
-		return fmt.Errorf("failed to push to queue: %w", err)
+	// Push to the resource's list (LPUSH adds to the beginning - most recent first) and keep
+	// only the most recent maxSize changes
+	key := changesKey(resourceKey)
+	if err := rm.client.LPush(ctx, key, string(data)).Err(); err != nil {
+		return fmt.Errorf("failed to push change for %s: %w", resourceKey, err)
+	}
+	if err := rm.client.LTrim(ctx, key, 0, int64(rm.maxSize-1)).Err(); err != nil {
+		return fmt.Errorf("failed to trim changes for %s: %w", resourceKey, err)
+	}
+	if err := rm.expireResourceKey(ctx, key); err != nil {
+		return err
 	}
 
-	// Trim queue to maxSize (keep only the most recent N changes)
-	// When queue is full and new item added, oldest gets removed automatically
-	if err := rm.client.LTrim(ctx, rm.queueName, 0, int64(rm.maxSize-1)).Err(); err != nil {
-		return fmt.Errorf("failed to trim queue: %w", err)
+	// Recent-activity feed: all changes from all resources, trimmed the same way
+	if rm.recentChangesFeed {
+		if err := rm.client.LPush(ctx, rm.queueName, string(data)).Err(); err != nil {
+			return fmt.Errorf("failed to push to queue: %w", err)
+		}
+		if err := rm.client.LTrim(ctx, rm.queueName, 0, int64(rm.maxSize-1)).Err(); err != nil {
+			return fmt.Errorf("failed to trim queue: %w", err)
+		}
 	}
 
 	rm.logResourceChange(change, change.Version)
 	return nil
 }
 
-// GetResourceChanges retrieves all changes from the global queue
+// GetResourceChanges retrieves the stored changes of one resource, most recent first
 func (rm *RedisManager) GetResourceChanges(resourceKey string) ([]ResourceChange, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	// Get all items from the queue
-	results, err := rm.client.LRange(ctx, rm.queueName, 0, -1).Result()
+	results, err := rm.client.LRange(ctx, changesKey(resourceKey), 0, -1).Result()
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve from queue: %w", err)
+		return nil, fmt.Errorf("failed to retrieve changes for %s: %w", resourceKey, err)
 	}
 
 	changes := make([]ResourceChange, 0, len(results))
-
-	// Unmarshal each result and filter by resourceKey if needed
 	for _, result := range results {
 		var change ResourceChange
 		if err := json.Unmarshal([]byte(result), &change); err != nil {
@@ -145,28 +388,24 @@ func (rm *RedisManager) GetResourceChanges(resourceKey string) ([]ResourceChange
 	return changes, nil
 }
 
-// GetAllObjects retrieves all objects from all resource keys
-func (rm *RedisManager) GetAllObjects() ([]interface{}, error) {
+// GetResourceObjects retrieves all versions of a specific resource
+func (rm *RedisManager) GetResourceObjects(resourceKey string) ([]interface{}, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	// Get all keys matching the pattern (kind/name/namespace)
-	keys, err := rm.client.Keys(ctx, "*/*/*").Result()
+	// Get all items from the resource-specific key
+	results, err := rm.client.LRange(ctx, resourceKey, 0, -1).Result()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get resource keys: %w", err)
+		return nil, fmt.Errorf("failed to get objects from resource key %s: %w", resourceKey, err)
 	}
 
-	objects := make([]interface{}, 0)
-
-	// For each key, get the most recent object (index 0)
-	for _, key := range keys {
-		results, err := rm.client.LRange(ctx, key, 0, 0).Result()
-		if err != nil || len(results) == 0 {
-			continue
-		}
+	// Unmarshal each result as a generic object, rebuilding versions stored as diffs
+	history := decodeHistory(results)
+	reconstructHistory(history)
 
-		var obj interface{}
-		if err := json.Unmarshal([]byte(results[0]), &obj); err != nil {
+	objects := make([]interface{}, 0, len(history))
+	for _, obj := range history {
+		if obj == nil {
 			continue // Skip invalid JSON
 		}
 		objects = append(objects, obj)
@@ -175,29 +414,157 @@ func (rm *RedisManager) GetAllObjects() ([]interface{}, error) {
 	return objects, nil
 }
 
-// GetResourceObjects retrieves all versions of a specific resource
-func (rm *RedisManager) GetResourceObjects(resourceKey string) ([]interface{}, error) {
+// GetResourceHistoryRaw returns the raw stored JSON entries of a resource (most recent first)
+func (rm *RedisManager) GetResourceHistoryRaw(resourceKey string) ([]string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	// Get all items from the resource-specific key
 	results, err := rm.client.LRange(ctx, resourceKey, 0, -1).Result()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get objects from resource key %s: %w", resourceKey, err)
+		return nil, fmt.Errorf("failed to get history from resource key %s: %w", resourceKey, err)
 	}
+	return results, nil
+}
 
-	objects := make([]interface{}, 0, len(results))
+// ReplaceResourceHistory atomically replaces a resource's history with the given raw entries (most recent first).
+// Callers should hold LockResource for the key
+func (rm *RedisManager) ReplaceResourceHistory(resourceKey string, entries []string) error {
+	if rm.dryRun {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
-	// Unmarshal each result as a generic object
-	for _, result := range results {
-		var obj interface{}
-		if err := json.Unmarshal([]byte(result), &obj); err != nil {
-			continue // Skip invalid JSON
+	values := make([]interface{}, len(entries))
+	for i, entry := range entries {
+		values[i] = entry
+	}
+
+	pipe := rm.client.TxPipeline()
+	pipe.Del(ctx, resourceKey)
+	if len(values) > 0 {
+		pipe.RPush(ctx, resourceKey, values...)
+		if rm.ttl > 0 {
+			pipe.Expire(ctx, resourceKey, rm.ttl) // DEL dropped the previous expiry
 		}
-		objects = append(objects, obj)
 	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to replace history for resource key %s: %w", resourceKey, err)
+	}
+	return nil
+}
 
-	return objects, nil
+// GetLatestObject retrieves the most recent stored version of a resource (nil if none is stored)
+func (rm *RedisManager) GetLatestObject(resourceKey string) (interface{}, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	results, err := rm.client.LRange(ctx, resourceKey, 0, 0).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest object from resource key %s: %w", resourceKey, err)
+	}
+	if len(results) == 0 {
+		return nil, nil
+	}
+
+	var obj interface{}
+	if err := json.Unmarshal([]byte(results[0]), &obj); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal latest object for %s: %w", resourceKey, err)
+	}
+
+	// A version stored as a diff is rebuilt from the history before it
+	if isDiffEntry(obj) {
+		objects, err := rm.GetResourceObjects(resourceKey)
+		if err != nil {
+			return nil, err
+		}
+		if len(objects) == 0 {
+			return nil, nil
+		}
+		return objects[0], nil
+	}
+
+	return obj, nil
+}
+
+// LatestGeneration returns the generation and uid of the newest stored version of a resource
+// (LINDEX 0), or 0 and "" when nothing readable is stored
+func (rm *RedisManager) LatestGeneration(resourceKey string) (int64, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := rm.client.LIndex(ctx, resourceKey, 0).Result()
+	if err == redis.Nil {
+		return 0, "", nil
+	}
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to get latest version of %s: %w", resourceKey, err)
+	}
+
+	var entry interface{}
+	if err := json.Unmarshal([]byte(result), &entry); err != nil {
+		return 0, "", nil
+	}
+	metadata, _ := unwrapStoredObject(entry)["metadata"].(map[string]interface{})
+	uid, _ := metadata["uid"].(string)
+	return objectGeneration(entry), uid, nil
+}
+
+// DiffAgainstPrevious diffs current against the latest version stored for a resource, the way
+// /api/diff compares stored versions but without the SetDiffIgnorePaths fields. Returns nil
+// changes when nothing is stored yet
+func (rm *RedisManager) DiffAgainstPrevious(resourceKey string, current interface{}) ([]FieldChange, error) {
+	previous, err := rm.GetLatestObject(resourceKey)
+	if err != nil {
+		return nil, err
+	}
+	if previous == nil {
+		return nil, nil
+	}
+
+	old, err := withoutPaths(CleanKubernetesObject(unwrapStoredObject(previous)), diffIgnorePaths)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare previous version of %s: %w", resourceKey, err)
+	}
+	new, err := withoutPaths(CleanKubernetesObject(current), diffIgnorePaths)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare %s: %w", resourceKey, err)
+	}
+
+	changes, err := GetFieldChanges(old, new)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff %s against its previous version: %w", resourceKey, err)
+	}
+	return changes, nil
+}
+
+// SaveWatchResourceVersion stores the last-seen resourceVersion for a watcher
+func (rm *RedisManager) SaveWatchResourceVersion(checkpointKey string, resourceVersion string) error {
+	if rm.dryRun {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := rm.client.Set(ctx, checkpointKey, resourceVersion, 0).Err(); err != nil {
+		return fmt.Errorf("failed to save resourceVersion for %s: %w", checkpointKey, err)
+	}
+	return nil
+}
+
+// GetWatchResourceVersion returns the stored resourceVersion for a watcher ("" if none)
+func (rm *RedisManager) GetWatchResourceVersion(checkpointKey string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resourceVersion, err := rm.client.Get(ctx, checkpointKey).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get resourceVersion for %s: %w", checkpointKey, err)
+	}
+	return resourceVersion, nil
 }
 
 // GetAllResourceKeys retrieves all resource keys stored in Redis
@@ -214,12 +581,73 @@ func (rm *RedisManager) GetAllResourceKeys() ([]string, error) {
 	return keys, nil
 }
 
-// GetCurrentVersion returns the current version number for a resource (count from queue)
+// ScanResourceKeys incrementally scans resource keys (kind/name/namespace) with SCAN instead of KEYS.
+// It stops once budget keys have been collected (budget <= 0 means no limit) and reports whether
+// the result was truncated
+func (rm *RedisManager) ScanResourceKeys(budget int) ([]string, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	keys := make([]string, 0)
+	var cursor uint64
+	for {
+		batch, next, err := rm.client.Scan(ctx, cursor, "*/*/*", 100).Result()
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to scan resource keys: %w", err)
+		}
+		keys = append(keys, batch...)
+
+		if budget > 0 && len(keys) >= budget {
+			return keys[:budget], next != 0 || len(keys) > budget, nil
+		}
+		if next == 0 {
+			return keys, false, nil
+		}
+		cursor = next
+	}
+}
+
+// resourceVersionsKey is the hash holding each resource's latest change version (field = resource key).
+// A hash rather than one key per resource, since keys containing a resource key would match the
+// kind/name/namespace scan pattern
+const resourceVersionsKey = "resource_versions"
+
+// GetCurrentVersion returns the current version number for a resource (0 if it has no changes)
 func (rm *RedisManager) GetCurrentVersion(resourceKey string) (int64, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	// Count items for this resource in the queue
+	version, err := rm.client.HGet(ctx, resourceVersionsKey, resourceKey).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get current version: %w", err)
+	}
+	return version, nil
+}
+
+// nextVersion atomically increments and returns a resource's version counter. A resource without
+// a counter yet (changes pushed before counters existed) is seeded once from the global queue
+func (rm *RedisManager) nextVersion(ctx context.Context, resourceKey string) (int64, error) {
+	exists, err := rm.client.HExists(ctx, resourceVersionsKey, resourceKey).Result()
+	if err != nil {
+		return 0, err
+	}
+	if !exists {
+		seed, err := rm.queuedVersion(ctx, resourceKey)
+		if err != nil {
+			return 0, err
+		}
+		if err := rm.client.HSetNX(ctx, resourceVersionsKey, resourceKey, seed).Err(); err != nil {
+			return 0, err
+		}
+	}
+	return rm.client.HIncrBy(ctx, resourceVersionsKey, resourceKey, 1).Result()
+}
+
+// queuedVersion returns the highest version of a resource found in the global queue
+func (rm *RedisManager) queuedVersion(ctx context.Context, resourceKey string) (int64, error) {
 	results, err := rm.client.LRange(ctx, rm.queueName, 0, -1).Result()
 	if err != nil {
 		return 0, fmt.Errorf("failed to count versions: %w", err)
@@ -232,7 +660,7 @@ func (rm *RedisManager) GetCurrentVersion(resourceKey string) (int64, error) {
 			continue
 		}
 		// Count versions for this specific resource
-		key := fmt.Sprintf("%s/%s/%s", change.ResourceKind, change.Namespace, change.ResourceName)
+		key := fmt.Sprintf("%s/%s/%s", change.ResourceKind, change.ResourceName, change.Namespace)
 		if key == resourceKey && change.Version > version {
 			version = change.Version
 		}
@@ -256,6 +684,9 @@ func (rm *RedisManager) GetQueueSize() (int64, error) {
 
 // ClearQueue removes all changes from the queue
 func (rm *RedisManager) ClearQueue() error {
+	if rm.dryRun {
+		return nil
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -263,12 +694,19 @@ func (rm *RedisManager) ClearQueue() error {
 		return fmt.Errorf("failed to clear queue: %w", err)
 	}
 
-	fmt.Printf("✅ Queue '%s' cleared\n", rm.queueName)
+	logInfo(fmt.Sprintf("✅ Queue '%s' cleared", rm.queueName), "queue cleared", slog.String("queue", rm.queueName))
 	return nil
 }
 
 // logResourceChange logs the versioned resource change
 func (rm *RedisManager) logResourceChange(change ResourceChange, version int64) {
+	if structuredLogging() {
+		logInfo("", "resource change stored",
+			append(resourceAttrs(change.ResourceKind, change.Namespace, change.ResourceName),
+				slog.Int64("version", version), slog.Time("timestamp", change.Timestamp), slog.String("summary", change.Summary()))...)
+		return
+	}
+
 	fmt.Println()
 	fmt.Println("📝 RESOURCE CHANGE DETECTED AND STORED")
 	fmt.Println("================================================================================")
@@ -277,7 +715,7 @@ func (rm *RedisManager) logResourceChange(change ResourceChange, version int64)
 	fmt.Printf("   Namespace: %s\n", change.Namespace)
 	fmt.Printf("   Name: %s\n", change.ResourceName)
 	fmt.Printf("   Version: %d\n", version)
-	fmt.Printf("   Timestamp: %s\n", change.Timestamp.Format("2006-01-02 15:04:05"))
+	fmt.Printf("   Timestamp: %s\n", formatDisplayTime(change.Timestamp))
 
 	fmt.Println()
 	fmt.Println("   FULL OBJECT:")
@@ -346,9 +784,13 @@ func (rm *RedisManager) PrintLastNChanges(n int) error {
 			change.Namespace,
 			change.ResourceName,
 			change.Version,
-			change.Timestamp.Format("2006-01-02 15:04:05"),
+			formatDisplayTime(change.Timestamp),
 		)
 
+		if summary := change.Summary(); summary != "" {
+			fmt.Printf("   SUMMARY: %s\n", summary)
+		}
+
 		fmt.Println("   FULL OBJECT:")
 		objJSON, _ := json.MarshalIndent(change.Object, "      ", "  ")
 		fmt.Println(string(objJSON))
@@ -363,9 +805,3 @@ func (rm *RedisManager) PrintLastNChanges(n int) error {
 	fmt.Println("\n================================================================================")
 	return nil
 }
-
-// logObject logs a direct object to console in a simple format
-func (rm *RedisManager) logObject(obj interface{}) {
-	objJSON, _ := json.MarshalIndent(obj, "", "  ")
-	fmt.Println(string(objJSON))
-}
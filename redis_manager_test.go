@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+// concurrentStores is how many goroutines store to the same resource at once
+const concurrentStores = 50
+
+// assertGapFreeDescending fails unless versions (most recent first) are n, n-1, ..., 1
+func assertGapFreeDescending(t *testing.T, versions []int64, n int) {
+	t.Helper()
+	if len(versions) != n {
+		t.Fatalf("stored %d versions, want %d", len(versions), n)
+	}
+	for i, version := range versions {
+		if want := int64(n - i); version != want {
+			t.Fatalf("versions = %v, want strictly decreasing from %d to 1 without gaps", versions, n)
+		}
+	}
+}
+
+func TestConcurrentOutOfOrderEventsStoreMonotonicGenerations(t *testing.T) {
+	rm := newTestRedisManager(t)
+	ep := NewEventPipeline(concurrentStores, rm)
+
+	// Reconnecting watchers deliver the generations of one resource concurrently and out of order
+	generations := rand.Perm(concurrentStores)
+	var wg sync.WaitGroup
+	for _, i := range generations {
+		wg.Add(1)
+		go func(generation int64) {
+			defer wg.Done()
+			old := newTestRoute(fmt.Sprint(100+generation-1), generation-1, fmt.Sprintf("v%d.example.com", generation-1))
+			new := newTestRoute(fmt.Sprint(100+generation), generation, fmt.Sprintf("v%d.example.com", generation))
+			ep.storeVersionedResourceChange(routeEvent(EventTypeModified, new), old, &ChangeDetails{})
+		}(int64(i + 1))
+	}
+	wg.Wait()
+
+	history, err := rm.GetResourceObjects("HTTPRoute/web/default")
+	if err != nil {
+		t.Fatalf("GetResourceObjects: %v", err)
+	}
+	stored := make([]int64, 0, len(history))
+	for _, entry := range history {
+		stored = append(stored, objectGeneration(entry))
+	}
+	if len(stored) == 0 || stored[0] != concurrentStores {
+		t.Fatalf("stored generations = %v, want the newest to be %d", stored, concurrentStores)
+	}
+	// Late older generations are dropped rather than stored above newer ones
+	for i := 1; i < len(stored); i++ {
+		if stored[i] >= stored[i-1] {
+			t.Fatalf("stored generations = %v (most recent first), want strictly decreasing", stored)
+		}
+	}
+}
+
+func TestConcurrentResourceChangesGetMonotonicVersions(t *testing.T) {
+	rm := newTestRedisManager(t)
+	resourceKey := "HTTPRoute/web/default"
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrentStores; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			change := ResourceChange{ResourceKind: "HTTPRoute", Namespace: "default", ResourceName: "web"}
+			if err := rm.PushResourceChange(resourceKey, change); err != nil {
+				t.Errorf("PushResourceChange: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	changes, err := rm.GetResourceChanges(resourceKey)
+	if err != nil {
+		t.Fatalf("GetResourceChanges: %v", err)
+	}
+	versions := make([]int64, 0, len(changes))
+	for _, change := range changes {
+		versions = append(versions, change.Version)
+	}
+	assertGapFreeDescending(t, versions, concurrentStores)
+}
+
+func TestResourceChangeVersionsContinueFromQueuedChanges(t *testing.T) {
+	rm := newTestRedisManager(t)
+	resourceKey := "HTTPRoute/web/default"
+
+	// Changes queued before per-resource version counters existed
+	for version := int64(1); version <= 3; version++ {
+		change := ResourceChange{Version: version, ResourceKind: "HTTPRoute", Namespace: "default", ResourceName: "web"}
+		data, err := json.Marshal(change)
+		if err != nil {
+			t.Fatalf("json.Marshal: %v", err)
+		}
+		if err := rm.client.LPush(context.Background(), rm.queueName, data).Err(); err != nil {
+			t.Fatalf("LPush: %v", err)
+		}
+	}
+
+	change := ResourceChange{ResourceKind: "HTTPRoute", Namespace: "default", ResourceName: "web"}
+	if err := rm.PushResourceChange(resourceKey, change); err != nil {
+		t.Fatalf("PushResourceChange: %v", err)
+	}
+	changes, err := rm.GetResourceChanges(resourceKey)
+	if err != nil {
+		t.Fatalf("GetResourceChanges: %v", err)
+	}
+	if len(changes) != 1 || changes[0].Version != 4 {
+		t.Errorf("changes = %+v, want one change with version 4", changes)
+	}
+}
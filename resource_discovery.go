@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+// IsResourceServed checks via discovery whether the API server serves the given resource,
+// e.g. whether an optional CRD (Gateway API, Envoy Gateway) is installed
+func IsResourceServed(discoveryClient discovery.DiscoveryInterface, gvr schema.GroupVersionResource) (bool, error) {
+	resources, err := discoveryClient.ServerResourcesForGroupVersion(gvr.GroupVersion().String())
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to discover %s: %w", gvr.GroupVersion().String(), err)
+	}
+
+	for _, resource := range resources.APIResources {
+		if resource.Name == gvr.Resource {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// knownClusterScopedKinds are kinds known to be cluster-scoped, used when discovery can't tell
+// (e.g. a CRD that isn't installed yet)
+var knownClusterScopedKinds = map[string]bool{
+	"GatewayClass":                   true,
+	"MutatingWebhookConfiguration":   true,
+	"ValidatingWebhookConfiguration": true,
+	"Namespace":                      true,
+	"Node":                           true,
+	"ClusterRole":                    true,
+	"ClusterRoleBinding":             true,
+	"CustomResourceDefinition":       true,
+	"IngressClass":                   true,
+	"StorageClass":                   true,
+	"PersistentVolume":               true,
+}
+
+// ClusterScopedKinds returns which of the resources' kinds are cluster-scoped, asking discovery
+// and falling back to knownClusterScopedKinds for resources it can't find
+func ClusterScopedKinds(discoveryClient discovery.DiscoveryInterface, resources []ResourceConfig) map[string]bool {
+	kinds := make(map[string]bool)
+	for _, resource := range resources {
+		gvr := resource.ToGVR()
+		discovered := false
+		if list, err := discoveryClient.ServerResourcesForGroupVersion(gvr.GroupVersion().String()); err == nil {
+			for _, apiResource := range list.APIResources {
+				if apiResource.Name == gvr.Resource {
+					discovered = true
+					if !apiResource.Namespaced {
+						kinds[resource.Kind] = true
+					}
+					break
+				}
+			}
+		}
+		if !discovered && knownClusterScopedKinds[resource.Kind] {
+			kinds[resource.Kind] = true
+		}
+	}
+	return kinds
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"k8s.io/apimachinery/pkg/api/meta"
+)
+
+// duplicateEventsMetric counts events dropped for not being newer than the last processed
+// resourceVersion of their resource, by kind
+var duplicateEventsMetric = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "pipeline_duplicate_events_total",
+	Help: "Events dropped for replaying an already processed resourceVersion, by kind.",
+}, []string{"kind"})
+
+// SetResourceVersionDedup enables dropping events whose resourceVersion isn't newer than the last
+// one processed for the same resource, such as those a watch replays after reconnecting without
+// bookmarks. Enabled by default
+func (ep *EventPipeline) SetResourceVersionDedup(enabled bool) {
+	if enabled {
+		ep.lastVersions = make(map[string]string)
+	} else {
+		ep.lastVersions = nil
+	}
+}
+
+// isReplayed reports whether an event carries a resourceVersion already processed for its
+// resource, and otherwise records it as the latest. Refresh events (resyncs) re-send processed
+// versions on purpose, so they aren't counted as duplicates. Only called from the processing
+// goroutine
+func (ep *EventPipeline) isReplayed(key string, event ResourceEvent) bool {
+	if ep.lastVersions == nil {
+		return false
+	}
+	accessor, err := meta.Accessor(event.Object)
+	if err != nil || accessor.GetResourceVersion() == "" {
+		return false
+	}
+	version := accessor.GetResourceVersion()
+
+	if last, ok := ep.lastVersions[key]; ok && !resourceVersionNewer(version, last) {
+		if !event.Refresh {
+			duplicateEventsMetric.WithLabelValues(event.ResourceKind).Inc()
+		}
+		return true
+	}
+	if event.Type == EventTypeDeleted {
+		delete(ep.lastVersions, key) // a recreated object starts over with a newer version anyway
+	} else {
+		ep.lastVersions[key] = version
+	}
+	return false
+}
+
+// resourceVersionNewer reports whether version is newer than last. resourceVersions are opaque,
+// but the API server issues increasing integers; anything else only counts as older when equal
+func resourceVersionNewer(version, last string) bool {
+	v, vErr := strconv.ParseUint(version, 10, 64)
+	l, lErr := strconv.ParseUint(last, 10, 64)
+	if vErr != nil || lErr != nil {
+		return version != last
+	}
+	return v > l
+}
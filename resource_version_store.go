@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ResourceVersionStore checkpoints the last-seen resourceVersion of each watcher to Redis
+// so watches can resume after a restart. Writes are batched on an interval to keep them cheap.
+// A nil *ResourceVersionStore is valid and disables checkpointing
+type ResourceVersionStore struct {
+	redisManager *RedisManager
+	pending      map[string]string // checkpoint key -> resourceVersion not yet persisted
+	mutex        sync.Mutex
+	stopCh       chan struct{}
+}
+
+// NewResourceVersionStore creates a store that persists pending checkpoints every interval
+func NewResourceVersionStore(redisManager *RedisManager, interval time.Duration) *ResourceVersionStore {
+	store := &ResourceVersionStore{
+		redisManager: redisManager,
+		pending:      make(map[string]string),
+		stopCh:       make(chan struct{}),
+	}
+	go store.flushLoop(interval)
+	return store
+}
+
+// CheckpointKey builds the checkpoint key for a watcher.
+// It deliberately contains no "/" so it never matches the kind/name/namespace resource keys
+func CheckpointKey(gvr schema.GroupVersionResource, namespace string) string {
+	if namespace == "" {
+		namespace = "_all"
+	}
+	return fmt.Sprintf("watch_rv:%s.%s.%s:%s", gvr.Resource, gvr.Version, gvr.Group, namespace)
+}
+
+// Load returns the stored resourceVersion for a watcher ("" if none)
+func (s *ResourceVersionStore) Load(key string) string {
+	if s == nil {
+		return ""
+	}
+	resourceVersion, err := s.redisManager.GetWatchResourceVersion(key)
+	if err != nil {
+		logWarn(fmt.Sprintf("⚠️  Failed to load resourceVersion checkpoint %s: %v", key, err),
+			"failed to load resourceVersion checkpoint", slog.String("checkpoint", key), slog.String("error", err.Error()))
+		return ""
+	}
+	return resourceVersion
+}
+
+// Record notes the latest resourceVersion seen by a watcher; it is persisted on the next flush
+func (s *ResourceVersionStore) Record(key string, resourceVersion string) {
+	if s == nil || resourceVersion == "" {
+		return
+	}
+	s.mutex.Lock()
+	s.pending[key] = resourceVersion
+	s.mutex.Unlock()
+}
+
+// Stop persists any pending checkpoints and stops the flush loop
+func (s *ResourceVersionStore) Stop() {
+	if s == nil {
+		return
+	}
+	close(s.stopCh)
+	s.flush()
+}
+
+// flushLoop periodically persists pending checkpoints
+func (s *ResourceVersionStore) flushLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.flush()
+		case <-s.stopCh:
+			return
+		}
+	}
+}
+
+// flush writes all pending checkpoints to Redis
+func (s *ResourceVersionStore) flush() {
+	s.mutex.Lock()
+	pending := s.pending
+	s.pending = make(map[string]string)
+	s.mutex.Unlock()
+
+	for key, resourceVersion := range pending {
+		if err := s.redisManager.SaveWatchResourceVersion(key, resourceVersion); err != nil {
+			logWarn(fmt.Sprintf("⚠️  Failed to save resourceVersion checkpoint %s: %v", key, err),
+				"failed to save resourceVersion checkpoint", slog.String("checkpoint", key), slog.String("error", err.Error()))
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+// RollbackFieldManager is the field manager that owns fields restored by a rollback
+const RollbackFieldManager = "k8s-crud-rollback"
+
+// rollbackStrippedMetadata are metadata fields set by the API server, dropped before re-applying
+var rollbackStrippedMetadata = []string{
+	"resourceVersion", "uid", "managedFields", "generation", "creationTimestamp",
+	"selfLink", "deletionTimestamp", "deletionGracePeriodSeconds",
+}
+
+// rollbackObject returns the object of a stored version ready to apply: status and server-managed
+// metadata are removed. Fails if a redacted field would overwrite the live value
+func rollbackObject(stored interface{}) (*unstructured.Unstructured, error) {
+	objMap := unwrapStoredObject(stored)
+	if objMap == nil {
+		return nil, fmt.Errorf("stored version is not an object")
+	}
+	obj := (&unstructured.Unstructured{Object: objMap}).DeepCopy()
+
+	for _, path := range redactPaths(obj.GetKind()) {
+		fields := fieldPathSegments(obj.Object, path)
+		if fields == nil {
+			continue
+		}
+		value, _, _ := unstructured.NestedFieldNoCopy(obj.Object, fields...)
+		if isRedacted(value) {
+			return nil, fmt.Errorf("stored version has %s redacted and can't be restored", path)
+		}
+	}
+
+	for _, field := range rollbackStrippedMetadata {
+		unstructured.RemoveNestedField(obj.Object, "metadata", field)
+	}
+	unstructured.RemoveNestedField(obj.Object, "status")
+	return obj, nil
+}
+
+// Rollback server-side applies an object prepared by rollbackObject to the cluster, taking over
+// any fields other managers changed since. Returns the applied object
+func (wm *WatcherManager) Rollback(ctx context.Context, obj *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+	wm.mutex.RLock()
+	resource, ok := wm.resources[obj.GetKind()]
+	wm.mutex.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("resource kind %s is not being watched", obj.GetKind())
+	}
+
+	// Cluster-scoped objects have no namespace
+	var client dynamic.ResourceInterface = wm.dynamicClient.Resource(resource.ToGVR())
+	if obj.GetNamespace() != "" {
+		client = wm.dynamicClient.Resource(resource.ToGVR()).Namespace(obj.GetNamespace())
+	}
+
+	applied, err := client.Apply(ctx, obj.GetName(), obj, metav1.ApplyOptions{FieldManager: RollbackFieldManager, Force: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply %s %s/%s: %w", obj.GetKind(), obj.GetNamespace(), obj.GetName(), err)
+	}
+	return redactObject(applied), nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+// Snapshotter periodically stores a baseline snapshot of every configured resource, even when
+// nothing changed, as point-in-time compliance evidence ("this was the state at midnight").
+// Baselines are tagged in Redis so the history API can tell them apart from change-driven versions
+type Snapshotter struct {
+	dynamicClient dynamic.Interface
+	redisManager  *RedisManager
+	resources     []ResourceConfig
+	interval      time.Duration
+	opts          WatchOptions
+}
+
+// NewSnapshotter creates a snapshotter for the given resources running every interval.
+// It shares the watchers' options (circuit breaker, metadata client)
+func NewSnapshotter(dynamicClient dynamic.Interface, redisManager *RedisManager, resources []ResourceConfig, interval time.Duration, opts WatchOptions) *Snapshotter {
+	return &Snapshotter{
+		dynamicClient: dynamicClient,
+		redisManager:  redisManager,
+		resources:     resources,
+		interval:      interval,
+		opts:          opts,
+	}
+}
+
+// Start takes a snapshot at every multiple of the interval (in UTC, so a 24h interval
+// runs at midnight) until ctx is cancelled (blocking)
+func (s *Snapshotter) Start(ctx context.Context) {
+	logInfo(fmt.Sprintf("📸 Baseline snapshots enabled (every %s)", s.interval),
+		"baseline snapshots enabled", slog.Duration("interval", s.interval))
+
+	for {
+		next := time.Now().UTC().Truncate(s.interval).Add(s.interval)
+		if !sleepWithContext(ctx, time.Until(next)) {
+			return
+		}
+
+		stored, err := s.SnapshotAll(ctx)
+		if err != nil {
+			logWarn(fmt.Sprintf("⚠️  Baseline snapshot incomplete: %v", err),
+				"baseline snapshot incomplete", slog.String("error", err.Error()))
+		}
+		logInfo(fmt.Sprintf("📸 Stored baseline snapshot of %d resources", stored),
+			"stored baseline snapshot", slog.Int("resources", stored))
+	}
+}
+
+// SnapshotAll lists every configured resource and stores its current state as a baseline.
+// Returns the number of objects stored and the last error encountered, if any
+func (s *Snapshotter) SnapshotAll(ctx context.Context) (int, error) {
+	stored := 0
+	var lastErr error
+
+	for _, resource := range s.resources {
+		scopes := resource.Namespaces
+		if len(scopes) == 0 {
+			scopes = []string{metav1.NamespaceAll}
+		}
+
+		// Metadata-only resources are snapshotted the same way they are watched
+		opts := s.opts
+		opts.MetadataOnly = resource.MetadataOnly
+
+		for _, scope := range scopes {
+			var list *unstructured.UnstructuredList
+			err := opts.Breaker.Do(ctx, func() error {
+				var listErr error
+				client := newResourceClient(s.dynamicClient, resource.ToGVR(), scope, resource.Kind, opts)
+				list, listErr = client.List(ctx, resource.ListOptions())
+				return listErr
+			})
+			if err != nil {
+				lastErr = fmt.Errorf("failed to list %s: %w", resource.Resource, err)
+				logWarn(fmt.Sprintf("⚠️  Baseline snapshot: %v", lastErr), "baseline snapshot list failed",
+					append(watchAttrs(resource.Kind, scope), slog.String("error", lastErr.Error()))...)
+				continue
+			}
+
+			for _, item := range list.Items {
+				resourceKey := fmt.Sprintf("%s/%s/%s", resource.Kind, item.GetName(), item.GetNamespace())
+
+				unlock := s.redisManager.LockResource(resourceKey)
+				err := s.redisManager.PushBaselineObject(resourceKey, redactObject(item.DeepCopy()))
+				unlock()
+				if err != nil {
+					lastErr = err
+					logWarn(fmt.Sprintf("⚠️  Baseline snapshot of %s failed: %v", resourceKey, err), "baseline snapshot failed",
+						append(resourceAttrs(resource.Kind, item.GetNamespace(), item.GetName()), slog.String("error", err.Error()))...)
+					continue
+				}
+				stored++
+			}
+		}
+	}
+
+	return stored, lastErr
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// SetStatusKinds makes the pipeline record status-only updates of the given kinds (resources
+// configured with watchStatus), e.g. Gateway Programmed/Accepted transitions. Status changes of
+// every other kind are dropped as before
+func (ep *EventPipeline) SetStatusKinds(kinds []string) {
+	ep.statusKinds = make(map[string]bool, len(kinds))
+	for _, kind := range kinds {
+		ep.statusKinds[kind] = true
+	}
+}
+
+// StatusKinds returns the kinds of the resources that have watchStatus set
+func (wc *WatcherConfig) StatusKinds() []string {
+	kinds := make([]string, 0)
+	for _, resource := range wc.GetEnabledResources() {
+		if resource.WatchStatus {
+			kinds = append(kinds, resource.Kind)
+		}
+	}
+	return kinds
+}
+
+// diffStatus records the status change between two versions in changes.StatusChanges, with the
+// DiffJSON deltas describing which fields changed
+func diffStatus(old, new *unstructured.Unstructured, changes *ChangeDetails) {
+	oldStatus, _, _ := unstructured.NestedMap(old.Object, "status")
+	newStatus, _, _ := unstructured.NestedMap(new.Object, "status")
+
+	result, err := DiffJSON(oldStatus, newStatus)
+	if errors.Is(err, ErrDiffTooLarge) {
+		changes.StatusChanges["status"] = DiffSkippedMarker
+		changes.DiffSkipped = err.Error()
+		return
+	}
+	if err != nil {
+		logWarn(fmt.Sprintf("⚠️  Failed to diff status of %s %s/%s: %v", new.GetKind(), new.GetNamespace(), new.GetName(), err),
+			"failed to diff status", append(resourceAttrs(new.GetKind(), new.GetNamespace(), new.GetName()), slog.String("error", err.Error()))...)
+		return
+	}
+	if !result.HasChanges {
+		return
+	}
+
+	changes.StatusChanges["status"] = map[string]interface{}{
+		"old":    oldStatus,
+		"new":    newStatus,
+		"deltas": result.Deltas,
+	}
+}
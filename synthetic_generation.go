@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// storedVersionsKey is the Redis hash of per-resource stored version counters (field: resource
+// key), numbering the versions of objects that have no metadata.generation
+const storedVersionsKey = "resource_stored_versions"
+
+// nextStoredVersion increments and returns a resource's stored version counter. The counter
+// outlives trimming and expiry of the history, so numbers are never reused
+func (rm *RedisManager) nextStoredVersion(ctx context.Context, resourceKey string) (int64, error) {
+	version, err := rm.client.HIncrBy(ctx, storedVersionsKey, resourceKey, 1).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to number stored version of %s: %w", resourceKey, err)
+	}
+	return version, nil
+}
+
+// storedVersion returns the synthetic version number of a stored history entry (0 = none)
+func storedVersion(entry interface{}) int64 {
+	entryMap, ok := entry.(map[string]interface{})
+	if !ok {
+		return 0
+	}
+	version, _ := entryMap["stored_version"].(float64)
+	return int64(version)
+}
+
+// getObjectGeneration returns a stored entry's generation. Objects without one (e.g. ConfigMaps)
+// fall back to the entry's stored version, so every version has its own generation key
+func getObjectGeneration(obj interface{}) int64 {
+	if generation := objectGeneration(obj); generation != 0 {
+		return generation
+	}
+	return storedVersion(obj)
+}
+
+// isSyntheticGeneration reports whether getObjectGeneration numbers the entry by stored version
+func isSyntheticGeneration(obj interface{}) bool {
+	return objectGeneration(obj) == 0 && storedVersion(obj) != 0
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"os"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+const (
+	defaultTerminalWidth = 120 // used when stdout is not a terminal (pipes, log collectors)
+	diffValueIndent      = 30  // room left for the indent and "OLD: " prefix of a diff value line
+	minDiffValueWidth    = 40
+	apiDiffValueWidth    = 100 // fixed width for values rendered into API responses
+)
+
+// colorEnabled reports whether diff output may use ANSI colors: only when stdout is a
+// terminal and the NO_COLOR convention (https://no-color.org) isn't in effect
+func colorEnabled() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// terminalWidth returns the width of the terminal attached to stdout,
+// or defaultTerminalWidth when stdout is not a terminal
+func terminalWidth() int {
+	width, _, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || width <= 0 {
+		return defaultTerminalWidth
+	}
+	return width
+}
+
+// diffValueWidth returns how many characters of a value fit on one diff line
+func diffValueWidth() int {
+	width := terminalWidth() - diffValueIndent
+	if width < minDiffValueWidth {
+		return minDiffValueWidth
+	}
+	return width
+}
+
+// truncate shortens s to at most width characters, marking the cut with "...".
+// It counts and cuts on runes so multi-byte characters are never split
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:width]) + "..."
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// pipelineTracerName is the instrumentation scope of the event pipeline's spans
+const pipelineTracerName = "github.com/k8s-crud/event-pipeline"
+
+// noopTracer records nothing, used while tracing is disabled
+var noopTracer trace.Tracer = noop.NewTracerProvider().Tracer(pipelineTracerName)
+
+// NewTracerProvider creates an OpenTelemetry tracer provider that batches spans and exports them
+// over OTLP/HTTP to a collector endpoint (e.g. localhost:4318 or https://collector:4318).
+// Sampling follows the standard OTEL_TRACES_SAMPLER variables (parent-based always-on by default).
+// The provider and the W3C trace context propagator are installed globally.
+// Returns nil if endpoint is empty so tracing stays disabled
+func NewTracerProvider(ctx context.Context, endpoint string, serviceName string) (*sdktrace.TracerProvider, error) {
+	if endpoint == "" {
+		return nil, nil
+	}
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OTLP endpoint %q: %w", endpoint, err)
+	}
+
+	options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(endpointURL.Host)}
+	if endpointURL.Scheme == "http" {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+	if path := strings.TrimSuffix(endpointURL.Path, "/"); path != "" {
+		options = append(options, otlptracehttp.WithURLPath(path+"/v1/traces"))
+	}
+	exporter, err := otlptracehttp.New(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", serviceName))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return provider, nil
+}
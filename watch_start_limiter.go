@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// WatchStartLimiter bounds how many (resource, namespace) watches are being brought up at once:
+// initial List plus opening the watch connection. A watch holds its slot only until it is
+// established, so every configured watch still comes online, just without opening hundreds of
+// connections in one burst on wide multi-namespace configs
+type WatchStartLimiter struct {
+	slots  chan struct{}
+	total  int
+	online int
+	mutex  sync.Mutex
+}
+
+// NewWatchStartLimiter creates a limiter allowing up to concurrency watches to start in parallel.
+// total is the number of watches expected (used for progress reporting only)
+func NewWatchStartLimiter(concurrency int, total int) *WatchStartLimiter {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &WatchStartLimiter{
+		slots: make(chan struct{}, concurrency),
+		total: total,
+	}
+}
+
+// Acquire blocks until a start slot is free. Returns false if ctx was cancelled while waiting
+func (wl *WatchStartLimiter) Acquire(ctx context.Context) bool {
+	if wl == nil {
+		return true
+	}
+	select {
+	case wl.slots <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Online frees the slot of a watch that is now established and reports progress
+func (wl *WatchStartLimiter) Online(kind string, scope string) {
+	if wl == nil {
+		return
+	}
+	<-wl.slots
+
+	wl.mutex.Lock()
+	wl.online++
+	online := wl.online
+	wl.mutex.Unlock()
+
+	logInfo(fmt.Sprintf("🟢 Watches online: %d/%d (%s in %s)", online, wl.total, kind, scope), "watch online",
+		append(watchAttrs(kind, scope), slog.Int("online", online), slog.Int("total", wl.total))...)
+}
+
+// Abandon frees the slot of a watch that stopped before it was established
+func (wl *WatchStartLimiter) Abandon() {
+	if wl == nil {
+		return
+	}
+	<-wl.slots
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+// WatcherManager starts the watchers for configured resources and keeps track of them
+// so they can be operated on at runtime (e.g. a manual resync)
+type WatcherManager struct {
+	dynamicClient dynamic.Interface
+	pipeline      *EventPipeline
+	opts          WatchOptions
+	resources     map[string]ResourceConfig // watched resources by kind
+	late          map[string]bool           // kinds started after startup, see StartWhenServed
+	mutex         sync.RWMutex
+	resyncing     sync.Mutex // held while a resync runs, so resyncs don't overlap
+}
+
+// ErrResyncInProgress is returned by Resync and ResyncAll while another resync is running
+var ErrResyncInProgress = errors.New("a resync is already running")
+
+// NewWatcherManager creates a new watcher manager
+func NewWatcherManager(dynamicClient dynamic.Interface, pipeline *EventPipeline, opts WatchOptions) *WatcherManager {
+	return &WatcherManager{
+		dynamicClient: dynamicClient,
+		pipeline:      pipeline,
+		opts:          opts,
+		resources:     make(map[string]ResourceConfig),
+		late:          make(map[string]bool),
+	}
+}
+
+// Start starts watching a resource (non-blocking) until ctx is cancelled
+func (wm *WatcherManager) Start(ctx context.Context, resource ResourceConfig) {
+	wm.mutex.Lock()
+	wm.resources[resource.Kind] = resource
+	wm.mutex.Unlock()
+
+	go func() {
+		err := WatchResource(
+			ctx,
+			wm.dynamicClient,
+			resource.ToGVR(),
+			resource.Namespaces,
+			resource.Kind,
+			resource.ListOptions(),
+			wm.pipeline,
+			wm.optionsFor(resource),
+		)
+		if err != nil {
+			// Only this resource is affected; every other watcher keeps running
+			logError(fmt.Sprintf("❌ Watcher for %s (%s/%s) stopped: %v", resource.Kind, resource.Group, resource.Resource, err),
+				"watcher stopped", slog.String("kind", resource.Kind), slog.String("error", err.Error()))
+		}
+	}()
+}
+
+// optionsFor returns the shared watch options adjusted for one resource
+func (wm *WatcherManager) optionsFor(resource ResourceConfig) WatchOptions {
+	opts := wm.opts
+	opts.MetadataOnly = resource.MetadataOnly
+
+	wm.mutex.RLock()
+	defer wm.mutex.RUnlock()
+	if wm.late[resource.Kind] {
+		opts.ListLimiter = nil
+		opts.StartLimiter = nil
+	}
+	return opts
+}
+
+// IsWatched reports whether a resource kind is being watched
+func (wm *WatcherManager) IsWatched(kind string) bool {
+	wm.mutex.RLock()
+	defer wm.mutex.RUnlock()
+	_, ok := wm.resources[kind]
+	return ok
+}
+
+// Resync re-lists a watched resource kind and reconciles the pipeline's state and Redis against it:
+// every listed object is re-sent as ADDED, diffed against its latest stored version so missing or
+// stale history is stored again, and every known object that no longer exists is sent as DELETED.
+// If namespace is empty, all of the resource's configured namespaces are resynced.
+// Returns the number of resources reconciled
+func (wm *WatcherManager) Resync(ctx context.Context, kind string, namespace string) (int, error) {
+	if !wm.resyncing.TryLock() {
+		return 0, ErrResyncInProgress
+	}
+	defer wm.resyncing.Unlock()
+	return wm.resync(ctx, kind, namespace)
+}
+
+// ResyncAll resyncs every watched resource kind, see Resync. Returns the number of resources
+// reconciled per kind; a kind that fails doesn't stop the others
+func (wm *WatcherManager) ResyncAll(ctx context.Context) (map[string]int, error) {
+	if !wm.resyncing.TryLock() {
+		return nil, ErrResyncInProgress
+	}
+	defer wm.resyncing.Unlock()
+
+	wm.mutex.RLock()
+	kinds := make([]string, 0, len(wm.resources))
+	for kind := range wm.resources {
+		kinds = append(kinds, kind)
+	}
+	wm.mutex.RUnlock()
+	sort.Strings(kinds)
+
+	reconciled := make(map[string]int, len(kinds))
+	var errs []error
+	for _, kind := range kinds {
+		count, err := wm.resync(ctx, kind, "")
+		reconciled[kind] = count
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", kind, err))
+		}
+	}
+	return reconciled, errors.Join(errs...)
+}
+
+// resync is Resync without the guard against overlapping resyncs
+func (wm *WatcherManager) resync(ctx context.Context, kind string, namespace string) (int, error) {
+	wm.mutex.RLock()
+	resource, ok := wm.resources[kind]
+	wm.mutex.RUnlock()
+	if !ok {
+		return 0, fmt.Errorf("resource kind %s is not being watched", kind)
+	}
+
+	namespaces := resource.Namespaces
+	if namespace != "" {
+		namespaces = []string{namespace}
+	}
+
+	// An empty namespace list means the resource is watched across all namespaces
+	scopes := namespaces
+	if len(scopes) == 0 {
+		scopes = []string{metav1.NamespaceAll}
+	}
+
+	reconciled := 0
+	for _, scope := range scopes {
+		var list *unstructured.UnstructuredList
+		err := wm.opts.Breaker.Do(ctx, func() error {
+			var listErr error
+			client := newResourceClient(wm.dynamicClient, resource.ToGVR(), scope, kind, wm.optionsFor(resource))
+			list, listErr = client.List(ctx, resource.ListOptions())
+			return listErr
+		})
+		if err != nil {
+			return reconciled, fmt.Errorf("failed to list %s: %w", resource.Resource, err)
+		}
+
+		listed := make(map[string]bool, len(list.Items))
+		for _, item := range list.Items {
+			obj := redactObject(item.DeepCopy())
+			listed[obj.GetNamespace()+"/"+obj.GetName()] = true
+
+			wm.pipeline.SendEvent(ResourceEvent{
+				Type:          EventTypeAdded,
+				ResourceKind:  kind,
+				Namespace:     obj.GetNamespace(),
+				Name:          obj.GetName(),
+				Object:        obj,
+				Timestamp:     time.Now(),
+				ManagedFields: obj.GetManagedFields(),
+				Refresh:       true,
+			})
+			reconciled++
+		}
+
+		// Anything the pipeline still knows about but the API server no longer has was missed
+		for _, known := range wm.pipeline.KnownObjects(kind, scope) {
+			if listed[known.GetNamespace()+"/"+known.GetName()] {
+				continue
+			}
+
+			wm.pipeline.SendEvent(ResourceEvent{
+				Type:          EventTypeDeleted,
+				ResourceKind:  kind,
+				Namespace:     known.GetNamespace(),
+				Name:          known.GetName(),
+				Object:        known,
+				Timestamp:     time.Now(),
+				ManagedFields: known.GetManagedFields(),
+			})
+			reconciled++
+		}
+	}
+
+	logInfo(fmt.Sprintf("🔄 Resynced %s: %d resources reconciled", kind, reconciled),
+		"resynced", slog.String("kind", kind), slog.Int("reconciled", reconciled))
+	return reconciled, nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ImageChange is a container image update in a workload's pod template, the most audited
+// workload change
+type ImageChange struct {
+	Container string `json:"container"`
+	Init      bool   `json:"init,omitempty"` // an init container
+	Old       string `json:"old,omitempty"`  // empty when the container was added
+	New       string `json:"new,omitempty"`  // empty when the container was removed
+}
+
+// String describes the change, e.g. "container api: image v1.2.3→v1.2.4"
+func (ic ImageChange) String() string {
+	label := "container"
+	if ic.Init {
+		label = "init container"
+	}
+	switch {
+	case ic.Old == "":
+		return fmt.Sprintf("%s %s: added with image %s", label, ic.Container, ic.New)
+	case ic.New == "":
+		return fmt.Sprintf("%s %s: removed (image %s)", label, ic.Container, ic.Old)
+	}
+	return fmt.Sprintf("%s %s: image %s→%s", label, ic.Container, ic.Old, ic.New)
+}
+
+// podSpecPath is where a workload kind's pod template spec lives: a CronJob nests it in its job template
+func podSpecPath(kind string) []string {
+	if kind == "CronJob" {
+		return []string{"spec", "jobTemplate", "spec", "template", "spec"}
+	}
+	return []string{"spec", "template", "spec"}
+}
+
+// compareWorkloads extracts per-container image changes of a Deployment, StatefulSet, DaemonSet,
+// Job or CronJob, matching containers by name
+func compareWorkloads(old, new *unstructured.Unstructured) *ComparatorResult {
+	result := newComparatorResult()
+	podSpec := podSpecPath(new.GetKind())
+
+	for _, field := range []string{"initContainers", "containers"} {
+		oldContainers, _, _ := unstructured.NestedSlice(old.Object, append(podSpec, field)...)
+		newContainers, _, _ := unstructured.NestedSlice(new.Object, append(podSpec, field)...)
+
+		oldByName, oldOrder := listItemsByName(oldContainers)
+		newByName, newOrder := listItemsByName(newContainers)
+
+		for _, name := range newOrder {
+			newImage, _ := newByName[name]["image"].(string)
+			oldImage := ""
+			if oldContainer, exists := oldByName[name]; exists {
+				oldImage, _ = oldContainer["image"].(string)
+			}
+			if oldImage != newImage {
+				result.addImageChange(field, ImageChange{Container: name, Init: field == "initContainers", Old: oldImage, New: newImage})
+			}
+		}
+		for _, name := range oldOrder {
+			if _, exists := newByName[name]; !exists {
+				oldImage, _ := oldByName[name]["image"].(string)
+				result.addImageChange(field, ImageChange{Container: name, Init: field == "initContainers", Old: oldImage})
+			}
+		}
+	}
+
+	return result
+}
+
+// addImageChange records an image change both as a first-class ImageChange and as a named change
+func (cr *ComparatorResult) addImageChange(field string, change ImageChange) {
+	cr.ImageChanges = append(cr.ImageChanges, change)
+	cr.addChange(fmt.Sprintf("spec.template.spec.%s[%s].image", field, change.Container), change.Old, change.New)
+}
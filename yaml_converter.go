@@ -3,18 +3,39 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/yaml"
 )
 
-// CleanKubernetesObject removes only the verbose last-applied-configuration annotation
+// CleanOptions controls what CleanKubernetesObjectWithOptions keeps
+type CleanOptions struct {
+	KeepStatus        bool // include .status (false gives a spec-focused config view)
+	KeepManagedFields bool // include metadata.managedFields, which hold per-update timestamps
+}
+
+// DefaultCleanOptions returns the options used by CleanKubernetesObject (full object including status, without managedFields)
+func DefaultCleanOptions() CleanOptions {
+	return CleanOptions{KeepStatus: true}
+}
+
+// CleanKubernetesObject removes the verbose last-applied-configuration annotation and managedFields
 // Keeps ALL other fields: apiVersion, kind, full metadata (uid, resourceVersion, generation, etc.), spec, and status
 func CleanKubernetesObject(obj interface{}) map[string]interface{} {
+	return CleanKubernetesObjectWithOptions(obj, DefaultCleanOptions())
+}
+
+// CleanKubernetesObjectWithOptions is CleanKubernetesObject with control over which fields are kept
+func CleanKubernetesObjectWithOptions(obj interface{}, opts CleanOptions) map[string]interface{} {
 	// Convert to map for manipulation
 	objJSON, _ := json.Marshal(obj)
 	var objMap map[string]interface{}
 	json.Unmarshal(objJSON, &objMap)
 
+	// History stored before a field was redacted still holds its value
+	redactMap(objMap)
+
 	// Create cleaned object - keep everything
 	cleaned := make(map[string]interface{})
 
@@ -44,8 +65,10 @@ func CleanKubernetesObject(obj interface{}) map[string]interface{} {
 			}
 		}
 		
-		// Remove managedFields as it's very verbose (optional - comment out if you want to keep it)
-		delete(cleanedMetadata, "managedFields")
+		// Remove managedFields as it's very verbose, unless kept for its modification timestamps
+		if !opts.KeepManagedFields {
+			delete(cleanedMetadata, "managedFields")
+		}
 		
 		cleaned["metadata"] = cleanedMetadata
 	}
@@ -55,8 +78,8 @@ func CleanKubernetesObject(obj interface{}) map[string]interface{} {
 		cleaned["spec"] = spec
 	}
 
-	// Keep status (IMPORTANT - this was missing before!)
-	if status, ok := objMap["status"]; ok {
+	// Keep status unless a spec-focused view was requested
+	if status, ok := objMap["status"]; ok && opts.KeepStatus {
 		cleaned["status"] = status
 	}
 
@@ -65,8 +88,13 @@ func CleanKubernetesObject(obj interface{}) map[string]interface{} {
 
 // ConvertToYAML converts a Kubernetes object to YAML string (cleaned)
 func ConvertToYAML(obj interface{}) (string, error) {
+	return ConvertToYAMLWithOptions(obj, DefaultCleanOptions())
+}
+
+// ConvertToYAMLWithOptions converts a Kubernetes object to YAML string, cleaned with the given options
+func ConvertToYAMLWithOptions(obj interface{}, opts CleanOptions) (string, error) {
 	// First clean the object
-	cleanedObj := CleanKubernetesObject(obj)
+	cleanedObj := CleanKubernetesObjectWithOptions(obj, opts)
 
 	// Convert cleaned object to YAML
 	yamlData, err := yaml.Marshal(cleanedObj)
@@ -81,6 +109,11 @@ func ConvertToYAML(obj interface{}) (string, error) {
 // For generation 1: uses creationTimestamp
 // For generation > 1: uses the latest modification time from managedFields
 func ConvertToYAMLWithStoredMetadata(obj interface{}) (string, error) {
+	return ConvertToYAMLWithStoredMetadataOptions(obj, DefaultCleanOptions())
+}
+
+// ConvertToYAMLWithStoredMetadataOptions is ConvertToYAMLWithStoredMetadata with clean options
+func ConvertToYAMLWithStoredMetadataOptions(obj interface{}, opts CleanOptions) (string, error) {
 	// Extract generation from object
 	generation := getObjectGenerationFromObject(obj)
 
@@ -95,7 +128,7 @@ func ConvertToYAMLWithStoredMetadata(obj interface{}) (string, error) {
 	}
 
 	// Get clean YAML
-	yamlStr, err := ConvertToYAML(obj)
+	yamlStr, err := ConvertToYAMLWithOptions(obj, opts)
 	if err != nil {
 		return "", err
 	}
@@ -128,6 +161,29 @@ func ConvertToYAMLMultipleWithStoredMetadata(objects []interface{}) (string, err
 	return result, nil
 }
 
+// LoadUnstructuredFromYAML parses a single object's YAML, as returned by /api/generation, into an
+// unstructured object ready to pass to EnvoyGatewayClient. The timestamp/generation header that
+// ConvertToYAMLWithStoredMetadata prepends is dropped first
+func LoadUnstructuredFromYAML(yamlStr string) (*unstructured.Unstructured, error) {
+	body := yamlStr
+	if strings.HasPrefix(body, "timestamp:") {
+		if _, rest, found := strings.Cut(body, "\n---\n"); found {
+			body = rest
+		}
+	}
+
+	jsonData, err := yaml.YAMLToJSON([]byte(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(jsonData); err != nil {
+		return nil, fmt.Errorf("failed to decode object: %w", err)
+	}
+	return obj, nil
+}
+
 // getCreationTimestampFromObject extracts creationTimestamp from object metadata
 func getCreationTimestampFromObject(obj interface{}) string {
 	if obj == nil {